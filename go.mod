@@ -8,12 +8,14 @@ require (
 	github.com/Knetic/go-namedParameterQuery v0.0.0-20150709205813-b7327e472dfd
 	github.com/go-sql-driver/mysql v1.8.1
 	github.com/google/uuid v1.6.0
+	github.com/hamba/avro/v2 v2.27.0
 	github.com/jmoiron/sqlx v1.4.0
 	github.com/nats-io/nats.go v1.39.0
 	github.com/ozontech/allure-go/pkg/allure v0.6.13
 	github.com/ozontech/allure-go/pkg/framework v0.6.32
 	github.com/segmentio/kafka-go v0.4.47
 	gitlab.b2bdev.pro/backend/go-packages/log v0.6.0
+	google.golang.org/protobuf v1.36.6
 )
 
 require (
@@ -21,8 +23,12 @@ require (
 	github.com/cespare/xxhash/v2 v2.2.0 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
-	github.com/klauspost/compress v1.17.9 // indirect
+	github.com/json-iterator/go v1.1.12 // indirect
+	github.com/klauspost/compress v1.17.10 // indirect
 	github.com/kr/pretty v0.3.1 // indirect
+	github.com/mitchellh/mapstructure v1.5.0 // indirect
+	github.com/modern-go/concurrent v0.0.0-20180306012644-bacd9c7ef1dd // indirect
+	github.com/modern-go/reflect2 v1.0.2 // indirect
 	github.com/nats-io/nkeys v0.4.9 // indirect
 	github.com/nats-io/nuid v1.0.1 // indirect
 	github.com/pierrec/lz4/v4 v4.1.15 // indirect
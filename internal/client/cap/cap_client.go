@@ -1,6 +1,7 @@
 package cap
 
 import (
+	"context"
 	"net/http"
 
 	httpClient "CB_auto/internal/client"
@@ -13,8 +14,11 @@ import (
 
 type CapAPI interface {
 	// Common
+	WithContext(ctx context.Context) CapAPI
+	AsAdmin(sCtx provider.StepCtx, name string) (CapAPI, error)
 	GetToken(sCtx provider.StepCtx) string
 	CheckAdmin(sCtx provider.StepCtx, req *types.Request[models.AdminCheckRequestBody]) *types.Response[models.AdminCheckResponseBody]
+	RefreshAdminToken(sCtx provider.StepCtx, req *types.Request[models.AdminRefreshRequestBody]) *types.Response[models.AdminCheckResponseBody]
 
 	// Player
 	UpdateVerificationStatus(sCtx provider.StepCtx, req *types.Request[models.UpdateVerificationStatusRequestBody]) *types.Response[struct{}]
@@ -57,14 +61,38 @@ type CapAPI interface {
 type capClient struct {
 	client       *types.Client
 	tokenStorage *TokenStorage
+	admins       *adminPool
 }
 
 func NewClient(sCtx provider.StepCtx, cfg *config.Config, baseClient *types.Client) CapAPI {
 	client := &capClient{client: baseClient}
 	client.tokenStorage = NewTokenStorage(sCtx, cfg, client)
+	client.admins = newAdminPool(cfg, client.tokenStorage)
 	return client
 }
 
+// WithContext возвращает клиент, запросы которого отменяются вместе с ctx.
+func (c *capClient) WithContext(ctx context.Context) CapAPI {
+	return &capClient{
+		client:       c.client.WithContext(ctx),
+		tokenStorage: c.tokenStorage,
+		admins:       c.admins,
+	}
+}
+
+// AsAdmin возвращает клиент, GetToken которого выдает токен администратора name из config.HTTP.CapAdmins.
+func (c *capClient) AsAdmin(sCtx provider.StepCtx, name string) (CapAPI, error) {
+	storage, err := c.admins.get(sCtx, c, name)
+	if err != nil {
+		return nil, err
+	}
+	return &capClient{
+		client:       c.client,
+		tokenStorage: storage,
+		admins:       c.admins,
+	}, nil
+}
+
 func (c *capClient) GetToken(sCtx provider.StepCtx) string {
 	return c.tokenStorage.GetToken(sCtx)
 }
@@ -74,3 +102,9 @@ func (c *capClient) CheckAdmin(sCtx provider.StepCtx, req *types.Request[models.
 	req.Path = "/_cap/api/token/check"
 	return httpClient.DoRequest[models.AdminCheckRequestBody, models.AdminCheckResponseBody](sCtx, c.client, req)
 }
+
+func (c *capClient) RefreshAdminToken(sCtx provider.StepCtx, req *types.Request[models.AdminRefreshRequestBody]) *types.Response[models.AdminCheckResponseBody] {
+	req.Method = http.MethodPost
+	req.Path = "/_cap/api/token/refresh"
+	return httpClient.DoRequest[models.AdminRefreshRequestBody, models.AdminCheckResponseBody](sCtx, c.client, req)
+}
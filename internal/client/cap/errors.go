@@ -0,0 +1,31 @@
+package cap
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrEmptyToken   = errors.New("cap: response contains empty token")
+	ErrUnknownAdmin = errors.New("cap: unknown admin identity")
+)
+
+// LoginError возвращается, когда CAP отклонил логин или обновление токена администратора.
+type LoginError struct {
+	Admin      string
+	Operation  string
+	StatusCode int
+	Message    string
+	Err        error
+}
+
+func (e *LoginError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("cap: %s for admin %q failed: %v", e.Operation, e.Admin, e.Err)
+	}
+	return fmt.Sprintf("cap: %s for admin %q failed with status %d: %s", e.Operation, e.Admin, e.StatusCode, e.Message)
+}
+
+func (e *LoginError) Unwrap() error {
+	return e.Err
+}
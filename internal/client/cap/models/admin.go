@@ -9,3 +9,7 @@ type AdminCheckResponseBody struct {
 	Token        string `json:"token"`
 	RefreshToken string `json:"refreshToken"`
 }
+
+type AdminRefreshRequestBody struct {
+	RefreshToken string `json:"refreshToken"`
+}
@@ -1,67 +1,68 @@
 package cap
 
 import (
-	"encoding/base64"
-	"encoding/json"
 	"fmt"
-	"strings"
+	"net/http"
 	"sync"
 	"time"
 
 	"CB_auto/internal/client/cap/models"
 	"CB_auto/internal/client/types"
 	"CB_auto/internal/config"
+	"CB_auto/pkg/utils"
 
 	"github.com/ozontech/allure-go/pkg/framework/provider"
 )
 
+const DefaultAdmin = config.DefaultCapAdmin
+
 type TokenStorage struct {
 	mu        sync.RWMutex
 	token     string
 	refToken  string
 	expiresAt time.Time
 	client    CapAPI
-	config    *config.Config
-}
-
-type jwtClaims struct {
-	ExpiresAt int64 `json:"exp"`
+	name      string
+	admin     config.CapAdminConfig
 }
 
-func parseJWT(token string) (time.Time, error) {
-	parts := strings.Split(token, ".")
-	if len(parts) != 3 {
-		return time.Time{}, fmt.Errorf("invalid token format")
-	}
-
-	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
-	if err != nil {
-		return time.Time{}, fmt.Errorf("failed to decode payload: %v", err)
-	}
-
-	var claims jwtClaims
-	if err := json.Unmarshal(payload, &claims); err != nil {
-		return time.Time{}, fmt.Errorf("failed to parse claims: %v", err)
+func NewTokenStorage(sCtx provider.StepCtx, cfg *config.Config, client CapAPI) *TokenStorage {
+	admin := config.CapAdminConfig{
+		Username: cfg.HTTP.CapUsername,
+		Password: cfg.HTTP.CapPassword,
 	}
-
-	return time.Unix(claims.ExpiresAt, 0), nil
+	return NewAdminTokenStorage(sCtx, client, DefaultAdmin, admin)
 }
 
-func NewTokenStorage(sCtx provider.StepCtx, cfg *config.Config, client CapAPI) *TokenStorage {
+// NewAdminTokenStorage создает хранилище токена для именованного администратора CAP.
+func NewAdminTokenStorage(sCtx provider.StepCtx, client CapAPI, name string, admin config.CapAdminConfig) *TokenStorage {
 	storage := &TokenStorage{
 		client: client,
-		config: cfg,
+		name:   name,
+		admin:  admin,
+	}
+	if err := storage.refreshToken(sCtx); err != nil {
+		sCtx.Errorf("Failed to obtain CAP token: %v", err)
 	}
-	storage.refreshToken(sCtx)
 	return storage
 }
 
+// GetToken возвращает действующий токен. Ошибка получения токена отмечается в allure-шаге.
 func (s *TokenStorage) GetToken(sCtx provider.StepCtx) string {
+	token, err := s.Token(sCtx)
+	if err != nil {
+		sCtx.Errorf("Failed to obtain CAP token: %v", err)
+	}
+	return token
+}
+
+// Token возвращает действующий токен или *LoginError, если ни refresh, ни повторный логин не удались.
+func (s *TokenStorage) Token(sCtx provider.StepCtx) (string, error) {
 	s.mu.RLock()
 	if time.Until(s.expiresAt) > 3*time.Minute {
 		token := s.token
 		s.mu.RUnlock()
-		return token
+		return token, nil
 	}
 	s.mu.RUnlock()
 
@@ -69,30 +70,117 @@ func (s *TokenStorage) GetToken(sCtx provider.StepCtx) string {
 	defer s.mu.Unlock()
 
 	if time.Until(s.expiresAt) > 3*time.Minute {
-		return s.token
+		return s.token, nil
+	}
+
+	if err := s.refreshToken(sCtx); err != nil {
+		return "", err
+	}
+	return s.token, nil
+}
+
+// refreshToken сначала обменивает refresh-токен, а при неудаче выполняет полный логин.
+func (s *TokenStorage) refreshToken(sCtx provider.StepCtx) error {
+	if s.refToken != "" {
+		err := s.exchangeRefreshToken(sCtx)
+		if err == nil {
+			return nil
+		}
+		sCtx.Logf("CAP refresh token for admin %q rejected, falling back to login: %v", s.name, err)
+	}
+	return s.login(sCtx)
+}
+
+func (s *TokenStorage) exchangeRefreshToken(sCtx provider.StepCtx) error {
+	req := &types.Request[models.AdminRefreshRequestBody]{
+		Body: &models.AdminRefreshRequestBody{
+			RefreshToken: s.refToken,
+		},
 	}
 
-	s.refreshToken(sCtx)
-	return s.token
+	res := s.client.RefreshAdminToken(sCtx, req)
+	return s.storeTokens(sCtx, "token refresh", res)
 }
 
-func (s *TokenStorage) refreshToken(sCtx provider.StepCtx) {
+func (s *TokenStorage) login(sCtx provider.StepCtx) error {
 	req := &types.Request[models.AdminCheckRequestBody]{
 		Body: &models.AdminCheckRequestBody{
-			UserName: s.config.HTTP.CapUsername,
-			Password: s.config.HTTP.CapPassword,
+			UserName: s.admin.Username,
+			Password: s.admin.Password,
 		},
 	}
 
 	res := s.client.CheckAdmin(sCtx, req)
+	return s.storeTokens(sCtx, "login", res)
+}
+
+func (s *TokenStorage) storeTokens(sCtx provider.StepCtx, operation string, res *types.Response[models.AdminCheckResponseBody]) error {
+	if res.StatusCode != http.StatusOK || res.Error != nil {
+		loginErr := &LoginError{
+			Admin:      s.name,
+			Operation:  operation,
+			StatusCode: res.StatusCode,
+		}
+		if res.Error != nil {
+			loginErr.Message = res.Error.Message
+			if loginErr.Message == "" {
+				loginErr.Message = res.Error.Body
+			}
+		}
+		return loginErr
+	}
+	if res.Body.Token == "" {
+		return &LoginError{Admin: s.name, Operation: operation, StatusCode: res.StatusCode, Err: ErrEmptyToken}
+	}
+
 	s.token = res.Body.Token
 	s.refToken = res.Body.RefreshToken
 
-	expiresAt, err := parseJWT(res.Body.Token)
+	expiresAt, err := utils.ParseJWTExpiration(res.Body.Token)
 	if err != nil {
 		sCtx.Errorf("Failed to parse token expiration: %v", err)
 		s.expiresAt = time.Now().Add(30 * time.Minute)
 	} else {
 		s.expiresAt = expiresAt
 	}
+	return nil
+}
+
+// adminPool лениво создает хранилища токенов для администраторов из config.HTTP.CapAdmins.
+type adminPool struct {
+	mu       sync.Mutex
+	admins   map[string]config.CapAdminConfig
+	storages map[string]*TokenStorage
+}
+
+func newAdminPool(cfg *config.Config, defaultStorage *TokenStorage) *adminPool {
+	return &adminPool{
+		admins:   cfg.HTTP.CapAdmins,
+		storages: map[string]*TokenStorage{DefaultAdmin: defaultStorage},
+	}
+}
+
+func (p *adminPool) get(sCtx provider.StepCtx, client CapAPI, name string) (*TokenStorage, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if storage, ok := p.storages[name]; ok {
+		return storage, nil
+	}
+
+	admin, ok := p.admins[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownAdmin, name)
+	}
+
+	storage := &TokenStorage{
+		client: client,
+		name:   name,
+		admin:  admin,
+	}
+	if err := storage.refreshToken(sCtx); err != nil {
+		return nil, err
+	}
+	p.storages[name] = storage
+	return storage, nil
 }
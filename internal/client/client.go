@@ -2,6 +2,7 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -14,13 +15,11 @@ import (
 
 	"CB_auto/internal/client/types"
 	"CB_auto/internal/config"
-	"CB_auto/pkg/utils"
 
-	"github.com/ozontech/allure-go/pkg/allure"
 	"github.com/ozontech/allure-go/pkg/framework/provider"
 )
 
-func makeRequest[T any](serviceURL string, r *types.Request[T]) (*http.Request, []byte, error) {
+func makeRequest[T any](ctx context.Context, serviceURL string, r *types.Request[T]) (*http.Request, error) {
 	path := r.Path
 	if len(r.PathParams) > 0 {
 		for key, value := range r.PathParams {
@@ -31,12 +30,12 @@ func makeRequest[T any](serviceURL string, r *types.Request[T]) (*http.Request,
 
 	baseURL, err := url.Parse(serviceURL)
 	if err != nil {
-		return nil, nil, fmt.Errorf("invalid base url: %v", err)
+		return nil, fmt.Errorf("invalid base url: %v", err)
 	}
 
 	relURL, err := url.Parse(path)
 	if err != nil {
-		return nil, nil, fmt.Errorf("invalid path: %v", err)
+		return nil, fmt.Errorf("invalid path: %v", err)
 	}
 
 	fullURL := baseURL.ResolveReference(relURL)
@@ -48,7 +47,6 @@ func makeRequest[T any](serviceURL string, r *types.Request[T]) (*http.Request,
 		fullURL.RawQuery = query.Encode()
 	}
 
-	var bodyBytes []byte
 	var req *http.Request
 
 	if r.Multipart != nil {
@@ -57,44 +55,43 @@ func makeRequest[T any](serviceURL string, r *types.Request[T]) (*http.Request,
 
 		for name, value := range r.Multipart.Fields {
 			if err := writer.WriteField(name, value); err != nil {
-				return nil, nil, fmt.Errorf("failed to write field %s: %v", name, err)
+				return nil, fmt.Errorf("failed to write field %s: %v", name, err)
 			}
 		}
 
 		for field, file := range r.Multipart.Files {
 			part, err := writer.CreateFormFile(field, file.Filename)
 			if err != nil {
-				return nil, nil, fmt.Errorf("failed to create form file %s: %v", field, err)
+				return nil, fmt.Errorf("failed to create form file %s: %v", field, err)
 			}
 			if _, err := part.Write(file.Data); err != nil {
-				return nil, nil, fmt.Errorf("failed to write file data for %s: %v", field, err)
+				return nil, fmt.Errorf("failed to write file data for %s: %v", field, err)
 			}
 		}
 
 		if err := writer.Close(); err != nil {
-			return nil, nil, fmt.Errorf("failed to close multipart writer: %v", err)
+			return nil, fmt.Errorf("failed to close multipart writer: %v", err)
 		}
 
-		bodyBytes = body.Bytes()
-		req, err = http.NewRequest(r.Method, fullURL.String(), bytes.NewReader(bodyBytes))
+		req, err = http.NewRequestWithContext(ctx, r.Method, fullURL.String(), bytes.NewReader(body.Bytes()))
 		if err != nil {
-			return nil, nil, fmt.Errorf("request creation failed: %v", err)
+			return nil, fmt.Errorf("request creation failed: %v", err)
 		}
 
 		req.Header.Set("Content-Type", writer.FormDataContentType())
 	} else {
 		var bodyBuffer io.Reader
 		if r.Body != nil {
-			bodyBytes, err = json.Marshal(r.Body)
+			bodyBytes, err := json.Marshal(r.Body)
 			if err != nil {
-				return nil, nil, fmt.Errorf("failed to marshal request body: %v", err)
+				return nil, fmt.Errorf("failed to marshal request body: %v", err)
 			}
 			bodyBuffer = bytes.NewBuffer(bodyBytes)
 		}
 
-		req, err = http.NewRequest(r.Method, fullURL.String(), bodyBuffer)
+		req, err = http.NewRequestWithContext(ctx, r.Method, fullURL.String(), bodyBuffer)
 		if err != nil {
-			return nil, nil, fmt.Errorf("failed to create request: %v", err)
+			return nil, fmt.Errorf("failed to create request: %v", err)
 		}
 
 		if r.Body != nil && req.Header.Get("Content-Type") == "" {
@@ -106,7 +103,7 @@ func makeRequest[T any](serviceURL string, r *types.Request[T]) (*http.Request,
 		req.Header.Set(key, value)
 	}
 
-	return req, bodyBytes, nil
+	return req, nil
 }
 
 func InitClient(t provider.T, cfg *config.Config, clientType types.ClientType) *types.Client {
@@ -128,31 +125,55 @@ func InitClient(t provider.T, cfg *config.Config, clientType types.ClientType) *
 	return &types.Client{
 		ServiceURL: u.String(),
 		HttpClient: &http.Client{
-			Timeout: time.Duration(cfg.HTTP.Timeout) * time.Second,
+			Timeout: cfg.HTTP.Timeout,
 		},
+		RetryPolicy: types.NewRetryPolicy(cfg.HTTP.RetryAttempts, cfg.HTTP.RetryDelay),
+		Middlewares: DefaultMiddlewares(),
 	}
 }
 
 func DoRequest[T any, V any](sCtx provider.StepCtx, c *types.Client, request *types.Request[T]) *types.Response[V] {
-	req, bodyBytes, err := makeRequest(c.ServiceURL, request)
+	return DoRequestWithContext[T, V](c.Context(), sCtx, c, request)
+}
+
+// DoRequestWithContext выполняет запрос с учетом дедлайна и отмены ctx.
+// Повторные попытки выполняются согласно c.RetryPolicy, каждая попытка прикладывается к allure-шагу.
+func DoRequestWithContext[T any, V any](ctx context.Context, sCtx provider.StepCtx, c *types.Client, request *types.Request[T]) *types.Response[V] {
+	maxAttempts := c.RetryPolicy.Attempts(request.Method)
+
+	for attempt := 1; ; attempt++ {
+		response, retryable := doAttempt[T, V](ctx, sCtx, c, request, attempt, maxAttempts)
+		if !retryable || attempt >= maxAttempts {
+			return response
+		}
+
+		delay := c.RetryPolicy.Backoff(attempt, response.Headers)
+		log.Printf("Retrying request %s %s in %v (attempt %d/%d)", request.Method, request.Path, delay, attempt+1, maxAttempts)
+
+		select {
+		case <-ctx.Done():
+			log.Printf("Request cancelled before retry: %v", ctx.Err())
+			return response
+		case <-time.After(delay):
+		}
+	}
+}
+
+func doAttempt[T any, V any](ctx context.Context, sCtx provider.StepCtx, c *types.Client, request *types.Request[T], attempt, maxAttempts int) (*types.Response[V], bool) {
+	ctx = types.ContextWithAttempt(ctx, types.Attempt{Number: attempt, Max: maxAttempts})
+	req, err := makeRequest(ctx, c.ServiceURL, request)
 	if err != nil {
 		log.Printf("Failed to create request: %v", err)
 		return &types.Response[V]{
 			Error: &types.ErrorResponse{Body: err.Error()},
-		}
+		}, false
 	}
 
-	log.Printf("Request URL: %s, Method: %s, Headers: %+v", req.URL.String(), req.Method, req.Header)
-	if len(bodyBytes) > 0 {
-		log.Printf("Request Body: %s", string(bodyBytes))
-	}
-
-	resp, err := c.HttpClient.Do(req)
+	resp, err := c.Chain(transport(c))(sCtx, req)
 	if err != nil {
-		log.Printf("HTTP request failed: %v", err)
 		return &types.Response[V]{
 			Error: &types.ErrorResponse{Body: err.Error()},
-		}
+		}, ctx.Err() == nil
 	}
 	defer resp.Body.Close()
 
@@ -160,12 +181,12 @@ func DoRequest[T any, V any](sCtx provider.StepCtx, c *types.Client, request *ty
 	if err != nil {
 		log.Printf("Failed to read response body: %v", err)
 		return &types.Response[V]{
-			Error: &types.ErrorResponse{Body: err.Error()},
-		}
+			StatusCode: resp.StatusCode,
+			Headers:    resp.Header,
+			Error:      &types.ErrorResponse{Body: err.Error(), StatusCode: resp.StatusCode},
+		}, ctx.Err() == nil
 	}
 
-	log.Printf("Response Status: %d, Headers: %+v, Body: %s", resp.StatusCode, resp.Header, string(responseBody))
-
 	response := &types.Response[V]{
 		StatusCode: resp.StatusCode,
 		Headers:    resp.Header,
@@ -187,14 +208,7 @@ func DoRequest[T any, V any](sCtx provider.StepCtx, c *types.Client, request *ty
 			response.Error.Errors = errorObj.Errors
 		}
 
-		// Создаем и прикрепляем аттачменты для запроса и ответа
-		reqAttachment := allure.NewAttachment("HTTP request", allure.JSON, utils.CreateHttpAttachRequest(request))
-		respAttachment := allure.NewAttachment("HTTP error response", allure.JSON, utils.CreateHttpAttachResponse(response))
-
-		sCtx.WithAttachments(reqAttachment)
-		sCtx.WithAttachments(respAttachment)
-
-		return response
+		return response, c.RetryPolicy.ShouldRetryStatus(resp.StatusCode)
 	}
 
 	if len(responseBody) > 0 {
@@ -204,8 +218,11 @@ func DoRequest[T any, V any](sCtx provider.StepCtx, c *types.Client, request *ty
 		}
 	}
 
-	sCtx.WithAttachments(allure.NewAttachment("HTTP request", allure.JSON, utils.CreateHttpAttachRequest(request)))
-	sCtx.WithAttachments(allure.NewAttachment("HTTP response", allure.JSON, utils.CreateHttpAttachResponse(response)))
+	return response, false
+}
 
-	return response
+func transport(c *types.Client) types.Handler {
+	return func(_ provider.StepCtx, req *http.Request) (*http.Response, error) {
+		return c.HttpClient.Do(req)
+	}
 }
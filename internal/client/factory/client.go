@@ -3,8 +3,8 @@ package factory
 import (
 	"log"
 	"net/http"
-	"time"
 
+	httpClient "CB_auto/internal/client"
 	"CB_auto/internal/client/cap"
 	"CB_auto/internal/client/public"
 	"CB_auto/internal/client/types"
@@ -13,11 +13,14 @@ import (
 	"github.com/ozontech/allure-go/pkg/framework/provider"
 )
 
-func InitClient[T any](sCtx provider.StepCtx, cfg *config.Config, clientType types.ClientType) T {
+// InitClient создает клиент API. Переданные middlewares выполняются перед стандартными логированием и аттачментами.
+func InitClient[T any](sCtx provider.StepCtx, cfg *config.Config, clientType types.ClientType, middlewares ...types.Middleware) T {
 	baseClient := &types.Client{
 		HttpClient: &http.Client{
-			Timeout: time.Duration(cfg.HTTP.Timeout) * time.Second,
+			Timeout: cfg.HTTP.Timeout,
 		},
+		RetryPolicy: types.NewRetryPolicy(cfg.HTTP.RetryAttempts, cfg.HTTP.RetryDelay),
+		Middlewares: append(middlewares[:len(middlewares):len(middlewares)], httpClient.DefaultMiddlewares()...),
 	}
 
 	switch clientType {
@@ -0,0 +1,208 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"CB_auto/internal/client/types"
+	"CB_auto/pkg/redact"
+	"CB_auto/pkg/utils"
+
+	"github.com/google/uuid"
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+)
+
+// DefaultMiddlewares возвращает middleware, которые подключаются к каждому клиенту: логирование и allure-аттачменты.
+func DefaultMiddlewares() []types.Middleware {
+	return []types.Middleware{
+		LoggingMiddleware(),
+		AttachmentsMiddleware(),
+	}
+}
+
+// LoggingMiddleware пишет в лог запрос и ответ вместе с заголовками и телом.
+func LoggingMiddleware() types.Middleware {
+	return func(next types.Handler) types.Handler {
+		return func(sCtx provider.StepCtx, req *http.Request) (*http.Response, error) {
+			log.Printf("Request URL: %s, Method: %s, Headers: %+v", req.URL.String(), req.Method, outputHeaders(req.Context(), req.Header))
+			if body := readRequestBody(req); len(body) > 0 {
+				log.Printf("Request Body: %s", redact.JSON(body))
+			}
+
+			resp, err := next(sCtx, req)
+			if err != nil {
+				log.Printf("HTTP request failed: %v", err)
+				return resp, err
+			}
+
+			log.Printf("Response Status: %d, Headers: %+v, Body: %s", resp.StatusCode, outputHeaders(req.Context(), resp.Header), redact.JSON(readResponseBody(resp)))
+			return resp, nil
+		}
+	}
+}
+
+// AttachmentsMiddleware прикладывает запрос и ответ каждой попытки к allure-шагу.
+func AttachmentsMiddleware() types.Middleware {
+	return func(next types.Handler) types.Handler {
+		return func(sCtx provider.StepCtx, req *http.Request) (*http.Response, error) {
+			var suffix string
+			if attempt := types.AttemptFromContext(req.Context()); attempt.Max > 1 {
+				suffix = fmt.Sprintf(" (attempt %d/%d)", attempt.Number, attempt.Max)
+			}
+
+			reqAttachment := utils.CreateHttpAttachRawRequest(req, outputHeaders(req.Context(), req.Header), readRequestBody(req))
+
+			resp, err := next(sCtx, req)
+			sCtx.WithAttachments(allure.NewAttachment("HTTP request"+suffix, allure.JSON, reqAttachment))
+			if err != nil {
+				sCtx.WithAttachments(allure.NewAttachment("HTTP error"+suffix, allure.Text, []byte(err.Error())))
+				return resp, err
+			}
+
+			respName := "HTTP response"
+			if resp.StatusCode >= http.StatusBadRequest {
+				respName = "HTTP error response"
+			}
+			respAttachment := utils.CreateHttpAttachRawResponse(resp, outputHeaders(req.Context(), resp.Header), readResponseBody(resp))
+			sCtx.WithAttachments(allure.NewAttachment(respName+suffix, allure.JSON, respAttachment))
+			return resp, nil
+		}
+	}
+}
+
+// BearerAuthMiddleware подставляет заголовок Authorization, если он не задан в запросе явно.
+func BearerAuthMiddleware(token func(sCtx provider.StepCtx) string) types.Middleware {
+	return func(next types.Handler) types.Handler {
+		return func(sCtx provider.StepCtx, req *http.Request) (*http.Response, error) {
+			if req.Header.Get("Authorization") == "" {
+				if t := token(sCtx); t != "" {
+					req.Header.Set("Authorization", "Bearer "+t)
+				}
+			}
+			return next(sCtx, req)
+		}
+	}
+}
+
+// CorrelationIDMiddleware добавляет к запросу заголовок header с уникальным идентификатором.
+func CorrelationIDMiddleware(header string) types.Middleware {
+	return func(next types.Handler) types.Handler {
+		return func(sCtx provider.StepCtx, req *http.Request) (*http.Response, error) {
+			if req.Header.Get(header) == "" {
+				req.Header.Set(header, uuid.NewString())
+			}
+			return next(sCtx, req)
+		}
+	}
+}
+
+type RequestMetric struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Duration   time.Duration
+	Attempt    types.Attempt
+	Err        error
+}
+
+// MetricsMiddleware передает в observe длительность и результат каждого запроса.
+func MetricsMiddleware(observe func(metric RequestMetric)) types.Middleware {
+	return func(next types.Handler) types.Handler {
+		return func(sCtx provider.StepCtx, req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next(sCtx, req)
+
+			metric := RequestMetric{
+				Method:   req.Method,
+				URL:      req.URL.String(),
+				Duration: time.Since(start),
+				Attempt:  types.AttemptFromContext(req.Context()),
+				Err:      err,
+			}
+			if resp != nil {
+				metric.StatusCode = resp.StatusCode
+			}
+			observe(metric)
+			return resp, err
+		}
+	}
+}
+
+type redactedHeadersKey struct{}
+
+// RedactHeadersMiddleware скрывает значения заголовков headers в логах и аттачментах в дополнение к общим правилам redact.
+// Должен стоять в цепочке раньше LoggingMiddleware и AttachmentsMiddleware.
+func RedactHeadersMiddleware(headers ...string) types.Middleware {
+	return func(next types.Handler) types.Handler {
+		return func(sCtx provider.StepCtx, req *http.Request) (*http.Response, error) {
+			names := make(map[string]bool)
+			if existing, ok := req.Context().Value(redactedHeadersKey{}).(map[string]bool); ok {
+				for name := range existing {
+					names[name] = true
+				}
+			}
+			for _, header := range headers {
+				names[http.CanonicalHeaderKey(header)] = true
+			}
+			ctx := context.WithValue(req.Context(), redactedHeadersKey{}, names)
+			return next(sCtx, req.WithContext(ctx))
+		}
+	}
+}
+
+// outputHeaders скрывает заголовки по общим правилам redact и по RedactHeadersMiddleware запроса.
+func outputHeaders(ctx context.Context, header http.Header) http.Header {
+	redacted := redact.Headers(header)
+	names, ok := ctx.Value(redactedHeadersKey{}).(map[string]bool)
+	if !ok || len(names) == 0 {
+		return redacted
+	}
+
+	for name := range redacted {
+		if names[http.CanonicalHeaderKey(name)] {
+			redacted[name] = []string{redact.Mask}
+		}
+	}
+	return redacted
+}
+
+func readRequestBody(req *http.Request) []byte {
+	if req.GetBody == nil {
+		return nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil
+	}
+	defer body.Close()
+
+	data, _ := io.ReadAll(body)
+	return data
+}
+
+// readResponseBody вычитывает тело ответа и подменяет его копией, чтобы следующие обработчики могли прочитать его снова.
+func readResponseBody(resp *http.Response) []byte {
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	var reader io.Reader = bytes.NewReader(data)
+	if err != nil {
+		reader = io.MultiReader(reader, &errorReader{err: err})
+	}
+	resp.Body = io.NopCloser(reader)
+	return data
+}
+
+type errorReader struct {
+	err error
+}
+
+func (r *errorReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
@@ -54,6 +54,10 @@ type TokenCheckResponseBody struct {
 	RefreshToken string `json:"refreshToken"`
 }
 
+type RefreshTokenRequestBody struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
 type UpdatePlayerRequestBody struct {
 	FirstName        string `json:"firstName"`
 	LastName         string `json:"lastName"`
@@ -28,6 +28,12 @@ func (c *publicClient) TokenCheck(sCtx provider.StepCtx, req *types.Request[mode
 	return httpClient.DoRequest[models.TokenCheckRequestBody, models.TokenCheckResponseBody](sCtx, c.client, req)
 }
 
+func (c *publicClient) RefreshToken(sCtx provider.StepCtx, req *types.Request[models.RefreshTokenRequestBody]) *types.Response[models.TokenCheckResponseBody] {
+	req.Method = http.MethodPost
+	req.Path = "/_front_api/api/token/refresh"
+	return httpClient.DoRequest[models.RefreshTokenRequestBody, models.TokenCheckResponseBody](sCtx, c.client, req)
+}
+
 func (c *publicClient) UpdatePlayer(sCtx provider.StepCtx, req *types.Request[models.UpdatePlayerRequestBody]) *types.Response[models.UpdatePlayerResponseBody] {
 	req.Method = http.MethodPut
 	req.Path = "/_front_api/api/v1/player"
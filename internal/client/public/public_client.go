@@ -1,6 +1,8 @@
 package public
 
 import (
+	"context"
+
 	"CB_auto/internal/client/public/models"
 	"CB_auto/internal/client/types"
 
@@ -9,10 +11,14 @@ import (
 
 // PublicAPI объединяет все методы публичного API.
 type PublicAPI interface {
+	WithContext(ctx context.Context) PublicAPI
+	WithMiddleware(middlewares ...types.Middleware) PublicAPI
+
 	// Player методы
 	FastRegistration(sCtx provider.StepCtx, req *types.Request[models.FastRegistrationRequestBody]) *types.Response[models.FastRegistrationResponseBody]
 	FullRegistration(sCtx provider.StepCtx, req *types.Request[models.FullRegistrationRequestBody]) *types.Response[struct{}]
 	TokenCheck(sCtx provider.StepCtx, req *types.Request[models.TokenCheckRequestBody]) *types.Response[models.TokenCheckResponseBody]
+	RefreshToken(sCtx provider.StepCtx, req *types.Request[models.RefreshTokenRequestBody]) *types.Response[models.TokenCheckResponseBody]
 	UpdatePlayer(sCtx provider.StepCtx, req *types.Request[models.UpdatePlayerRequestBody]) *types.Response[models.UpdatePlayerResponseBody]
 	VerifyIdentity(sCtx provider.StepCtx, req *types.Request[models.VerifyIdentityRequestBody]) *types.Response[struct{}]
 	GetVerificationStatus(sCtx provider.StepCtx, req *types.Request[any]) *types.Response[[]models.VerificationStatusResponseItem]
@@ -49,3 +55,15 @@ type publicClient struct {
 func NewClient(baseClient *types.Client) PublicAPI {
 	return &publicClient{client: baseClient}
 }
+
+// WithContext возвращает клиент, запросы которого отменяются вместе с ctx.
+func (c *publicClient) WithContext(ctx context.Context) PublicAPI {
+	return &publicClient{client: c.client.WithContext(ctx)}
+}
+
+// WithMiddleware возвращает клиент с дополнительными middleware, исходный клиент не изменяется.
+func (c *publicClient) WithMiddleware(middlewares ...types.Middleware) PublicAPI {
+	client := c.client.Clone()
+	client.Use(middlewares...)
+	return &publicClient{client: client}
+}
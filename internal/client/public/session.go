@@ -0,0 +1,94 @@
+package public
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	httpClient "CB_auto/internal/client"
+	"CB_auto/internal/client/public/models"
+	"CB_auto/internal/client/types"
+	"CB_auto/pkg/utils"
+
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+)
+
+// PlayerSession — публичный API от имени конкретного игрока.
+// Токен подставляется в каждый запрос без явного заголовка Authorization и обновляется по refresh-токену до истечения.
+type PlayerSession struct {
+	PublicAPI
+
+	api          PublicAPI
+	mu           sync.RWMutex
+	token        string
+	refreshToken string
+	expiresAt    time.Time
+}
+
+func NewPlayerSession(sCtx provider.StepCtx, api PublicAPI, auth models.TokenCheckResponseBody) *PlayerSession {
+	session := &PlayerSession{api: api}
+	session.setTokens(sCtx, auth)
+	session.PublicAPI = api.WithMiddleware(httpClient.BearerAuthMiddleware(session.GetToken))
+	return session
+}
+
+// GetToken возвращает действующий токен. Ошибка обновления отмечается в allure-шаге, а запрос уходит без токена.
+func (s *PlayerSession) GetToken(sCtx provider.StepCtx) string {
+	token, err := s.Token(sCtx)
+	if err != nil {
+		sCtx.Errorf("Failed to refresh player token: %v", err)
+	}
+	return token
+}
+
+// Token возвращает действующий токен или ошибку, если refresh-токен отклонен. Истекший токен не возвращается.
+func (s *PlayerSession) Token(sCtx provider.StepCtx) (string, error) {
+	s.mu.RLock()
+	if time.Until(s.expiresAt) > 3*time.Minute {
+		token := s.token
+		s.mu.RUnlock()
+		return token, nil
+	}
+	s.mu.RUnlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if time.Until(s.expiresAt) > 3*time.Minute {
+		return s.token, nil
+	}
+
+	if err := s.refresh(sCtx); err != nil {
+		return "", err
+	}
+	return s.token, nil
+}
+
+func (s *PlayerSession) refresh(sCtx provider.StepCtx) error {
+	req := &types.Request[models.RefreshTokenRequestBody]{
+		Body: &models.RefreshTokenRequestBody{
+			RefreshToken: s.refreshToken,
+		},
+	}
+
+	res := s.api.RefreshToken(sCtx, req)
+	if res.StatusCode != http.StatusOK || res.Body.Token == "" {
+		return fmt.Errorf("public: player token refresh failed with status %d", res.StatusCode)
+	}
+	s.setTokens(sCtx, res.Body)
+	return nil
+}
+
+func (s *PlayerSession) setTokens(sCtx provider.StepCtx, auth models.TokenCheckResponseBody) {
+	s.token = auth.Token
+	s.refreshToken = auth.RefreshToken
+
+	expiresAt, err := utils.ParseJWTExpiration(auth.Token)
+	if err != nil {
+		sCtx.Errorf("Failed to parse token expiration: %v", err)
+		s.expiresAt = time.Now().Add(30 * time.Minute)
+	} else {
+		s.expiresAt = expiresAt
+	}
+}
@@ -0,0 +1,47 @@
+package types
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+)
+
+// Handler отправляет HTTP-запрос и возвращает ответ.
+type Handler func(sCtx provider.StepCtx, req *http.Request) (*http.Response, error)
+
+// Middleware оборачивает Handler: может изменить запрос до отправки и обработать ответ после.
+type Middleware func(next Handler) Handler
+
+// Use добавляет middleware в конец цепочки. Первый добавленный middleware вызывается первым.
+func (c *Client) Use(middlewares ...Middleware) {
+	c.Middlewares = append(c.Middlewares[:len(c.Middlewares):len(c.Middlewares)], middlewares...)
+}
+
+// Chain оборачивает transport всеми middleware клиента.
+func (c *Client) Chain(transport Handler) Handler {
+	handler := transport
+	for i := len(c.Middlewares) - 1; i >= 0; i-- {
+		handler = c.Middlewares[i](handler)
+	}
+	return handler
+}
+
+type Attempt struct {
+	Number int
+	Max    int
+}
+
+type attemptKey struct{}
+
+func ContextWithAttempt(ctx context.Context, attempt Attempt) context.Context {
+	return context.WithValue(ctx, attemptKey{}, attempt)
+}
+
+// AttemptFromContext возвращает номер текущей попытки запроса, по умолчанию 1 из 1.
+func AttemptFromContext(ctx context.Context) Attempt {
+	if attempt, ok := ctx.Value(attemptKey{}).(Attempt); ok {
+		return attempt
+	}
+	return Attempt{Number: 1, Max: 1}
+}
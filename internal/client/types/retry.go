@@ -0,0 +1,77 @@
+package types
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy описывает повторные попытки HTTP-запроса.
+// Повторяются только идемпотентные методы при сетевых ошибках и ответах 5xx/429.
+type RetryPolicy struct {
+	MaxAttempts int
+	Delay       time.Duration
+	MaxDelay    time.Duration
+	Methods     map[string]bool
+	Statuses    map[int]bool
+}
+
+var idempotentMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodOptions: true,
+	http.MethodPut:     true,
+	http.MethodDelete:  true,
+}
+
+func NewRetryPolicy(maxAttempts int, delay time.Duration) *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: maxAttempts,
+		Delay:       delay,
+		MaxDelay:    10 * delay,
+		Methods:     idempotentMethods,
+	}
+}
+
+// Attempts возвращает количество попыток для метода, минимум одна.
+func (p *RetryPolicy) Attempts(method string) int {
+	if p == nil || p.MaxAttempts < 1 || !p.Methods[method] {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// ShouldRetryStatus сообщает, нужно ли повторить запрос с таким статус-кодом.
+func (p *RetryPolicy) ShouldRetryStatus(statusCode int) bool {
+	if p == nil {
+		return false
+	}
+	if len(p.Statuses) > 0 {
+		return p.Statuses[statusCode]
+	}
+	return statusCode == http.StatusTooManyRequests || statusCode >= http.StatusInternalServerError
+}
+
+// Backoff возвращает паузу перед попыткой attempt+1. Заголовок Retry-After имеет приоритет.
+func (p *RetryPolicy) Backoff(attempt int, headers http.Header) time.Duration {
+	if p == nil {
+		return 0
+	}
+	if retryAfter := headers.Get("Retry-After"); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds >= 0 {
+			return p.capDelay(time.Duration(seconds) * time.Second)
+		}
+	}
+	delay := p.Delay
+	for i := 1; i < attempt && (p.MaxDelay <= 0 || delay < p.MaxDelay); i++ {
+		delay *= 2
+	}
+	return p.capDelay(delay)
+}
+
+func (p *RetryPolicy) capDelay(delay time.Duration) time.Duration {
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return delay
+}
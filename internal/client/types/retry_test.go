@@ -0,0 +1,95 @@
+package types
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyAttempts(t *testing.T) {
+	policy := NewRetryPolicy(3, time.Second)
+
+	tests := []struct {
+		name   string
+		policy *RetryPolicy
+		method string
+		want   int
+	}{
+		{name: "idempotent GET", policy: policy, method: http.MethodGet, want: 3},
+		{name: "idempotent DELETE", policy: policy, method: http.MethodDelete, want: 3},
+		{name: "POST is not retried", policy: policy, method: http.MethodPost, want: 1},
+		{name: "PATCH is not retried", policy: policy, method: http.MethodPatch, want: 1},
+		{name: "nil policy", policy: nil, method: http.MethodGet, want: 1},
+		{name: "zero attempts", policy: NewRetryPolicy(0, time.Second), method: http.MethodGet, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Attempts(tt.method); got != tt.want {
+				t.Errorf("Attempts(%s) = %d, want %d", tt.method, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyShouldRetryStatus(t *testing.T) {
+	policy := NewRetryPolicy(3, time.Second)
+	custom := NewRetryPolicy(3, time.Second)
+	custom.Statuses = map[int]bool{http.StatusConflict: true}
+
+	tests := []struct {
+		name   string
+		policy *RetryPolicy
+		status int
+		want   bool
+	}{
+		{name: "200", policy: policy, status: http.StatusOK, want: false},
+		{name: "400", policy: policy, status: http.StatusBadRequest, want: false},
+		{name: "429", policy: policy, status: http.StatusTooManyRequests, want: true},
+		{name: "500", policy: policy, status: http.StatusInternalServerError, want: true},
+		{name: "503", policy: policy, status: http.StatusServiceUnavailable, want: true},
+		{name: "custom statuses replace defaults", policy: custom, status: http.StatusInternalServerError, want: false},
+		{name: "custom status", policy: custom, status: http.StatusConflict, want: true},
+		{name: "nil policy", policy: nil, status: http.StatusInternalServerError, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.ShouldRetryStatus(tt.status); got != tt.want {
+				t.Errorf("ShouldRetryStatus(%d) = %t, want %t", tt.status, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := NewRetryPolicy(5, 100*time.Millisecond)
+
+	retryAfter := func(value string) http.Header {
+		headers := http.Header{}
+		headers.Set("Retry-After", value)
+		return headers
+	}
+
+	tests := []struct {
+		name    string
+		policy  *RetryPolicy
+		attempt int
+		headers http.Header
+		want    time.Duration
+	}{
+		{name: "first attempt", policy: policy, attempt: 1, want: 100 * time.Millisecond},
+		{name: "doubles", policy: policy, attempt: 2, want: 200 * time.Millisecond},
+		{name: "doubles again", policy: policy, attempt: 4, want: 800 * time.Millisecond},
+		{name: "capped by MaxDelay", policy: policy, attempt: 10, want: time.Second},
+		{name: "Retry-After seconds", policy: policy, attempt: 1, headers: retryAfter("0"), want: 0},
+		{name: "Retry-After capped", policy: policy, attempt: 1, headers: retryAfter("30"), want: time.Second},
+		{name: "invalid Retry-After ignored", policy: policy, attempt: 2, headers: retryAfter("soon"), want: 200 * time.Millisecond},
+		{name: "nil policy", policy: nil, attempt: 3, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Backoff(tt.attempt, tt.headers); got != tt.want {
+				t.Errorf("Backoff(%d) = %v, want %v", tt.attempt, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,12 +1,35 @@
 package types
 
 import (
+	"context"
 	"net/http"
 )
 
 type Client struct {
-	ServiceURL string
-	HttpClient *http.Client
+	ServiceURL  string
+	HttpClient  *http.Client
+	RetryPolicy *RetryPolicy
+	Middlewares []Middleware
+	ctx         context.Context
+}
+
+func (c *Client) Clone() *Client {
+	clone := *c
+	return &clone
+}
+
+// WithContext возвращает копию клиента, все запросы которой выполняются с ctx.
+func (c *Client) WithContext(ctx context.Context) *Client {
+	clone := c.Clone()
+	clone.ctx = ctx
+	return clone
+}
+
+func (c *Client) Context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
 }
 
 type ClientType string
@@ -1,21 +1,22 @@
 package config
 
 import (
-	"encoding/json"
 	"os"
 	"path/filepath"
 	"runtime"
 	"time"
 
+	"CB_auto/pkg/redact"
+
 	"github.com/ozontech/allure-go/pkg/framework/provider"
 )
 
 type MySQLCommonConfig struct {
-	DriverName     string `json:"driver_name"`
-	User           string `json:"user"`
+	DriverName     string `json:"driver_name" required:"true"`
+	User           string `json:"user" required:"true"`
 	Password       string `json:"password"`
-	Host           string `json:"host"`
-	Port           int    `json:"port"`
+	Host           string `json:"host" required:"true"`
+	Port           int    `json:"port" required:"true"`
 	DatabasePrefix string `json:"database_prefix"`
 }
 
@@ -24,29 +25,62 @@ type MySQLConfig struct {
 	DatabaseCore    string            `json:"database_core"`
 	DatabaseWallet  string            `json:"database_wallet"`
 	DatabaseBonus   string            `json:"database_bonus"`
-	PingTimeout     time.Duration     `json:"ping_timeout"`
-	ConnMaxLifetime time.Duration     `json:"conn_max_lifetime"`
-	ConnMaxIdleTime time.Duration     `json:"conn_max_idle_time"`
+	PingTimeout     time.Duration     `json:"ping_timeout" unit:"ns"`
+	ConnMaxLifetime time.Duration     `json:"conn_max_lifetime" unit:"ns"`
+	ConnMaxIdleTime time.Duration     `json:"conn_max_idle_time" unit:"ns"`
 	MaxOpenConns    int               `json:"max_open_conns"`
 	MaxIdleConns    int               `json:"max_idle_conns"`
 	RetryAttempts   int               `json:"retry_attempts"`
-	RetryDelay      time.Duration     `json:"retry_delay"`
+	RetryDelay      time.Duration     `json:"retry_delay" unit:"s"`
+	MaxRetryDelay   time.Duration     `json:"max_retry_delay" unit:"s"`
+	RetryTimeout    time.Duration     `json:"retry_timeout" unit:"s"`
+}
+
+// KafkaDecoderConfig описывает формат сообщений топика: json (по умолчанию), protobuf или avro.
+type KafkaDecoderConfig struct {
+	Format            string `json:"format"`
+	DescriptorSet     string `json:"descriptor_set"`
+	MessageType       string `json:"message_type"`
+	ConfluentFraming  bool   `json:"confluent_framing"`
+	UseProtoNames     bool   `json:"use_proto_names"`
+	SchemaRegistryURL string `json:"schema_registry_url"`
+	// SchemaDir заменяет schema registry в офлайн-запусках: схемы лежат в файлах <id>.avsc.
+	SchemaDir string `json:"schema_dir"`
 }
 
 type KafkaConfig struct {
-	Brokers     string        `json:"brokers"`
-	Timeout     time.Duration `json:"timeout"`
+	Brokers     string        `json:"brokers" required:"true"`
+	Timeout     time.Duration `json:"timeout" unit:"s"`
 	TopicPrefix string        `json:"topic_prefix"`
 	BufferSize  int           `json:"buffer_size"`
+	// GroupMode: partition (без группы, по умолчанию), run (группа на запуск, удаляется при закрытии)
+	// или shared (общая node.group_id).
+	GroupMode string `json:"group_mode"`
+	// Topics переопределяет суффиксы топиков, например {"limits.v2": "limits.v3"}.
+	Topics map[string]string `json:"topics"`
+	// EagerTopics — суффиксы топиков, readers которых запускаются при создании consumer
+	// (по умолчанию kafka.DefaultEagerTopics). Остальные топики читаются с первого поиска.
+	EagerTopics []string `json:"eager_topics"`
+	// Retention задает время хранения сообщений в памяти; buffer_size при этом ограничивает их число.
+	Retention time.Duration `json:"retention" unit:"s"`
+	// IndexFields — JSON-поля для индексации сообщений (по умолчанию kafka.DefaultIndexFields).
+	IndexFields []string `json:"index_fields"`
+	// Decoders задает формат сообщений по суффиксу топика.
+	Decoders map[string]KafkaDecoderConfig `json:"decoders"`
 }
 
 type NatsConfig struct {
-	Hosts         string        `json:"hosts"`
+	Hosts         string        `json:"hosts" required:"true"`
 	StreamPrefix  string        `json:"stream_prefix"`
-	ReconnectWait time.Duration `json:"reconnect_wait"`
+	ReconnectWait time.Duration `json:"reconnect_wait" unit:"s"`
 	MaxReconnects int           `json:"max_reconnects"`
-	Timeout       time.Duration `json:"timeout"`
-	StreamTimeout time.Duration `json:"stream_timeout"`
+	Timeout       time.Duration `json:"timeout" unit:"s"`
+	StreamTimeout time.Duration `json:"stream_timeout" unit:"s"`
+	// Lookback ограничивает чтение стрима для поиска без явной стартовой позиции последними Lookback.
+	// Не задан — стрим читается с начала.
+	Lookback time.Duration `json:"lookback" unit:"s"`
+	// Streams переопределяет имя стрима для домена темы (wallet, limits, bonus).
+	Streams map[string]string `json:"streams"`
 }
 
 type NodeConfig struct {
@@ -56,25 +90,39 @@ type NodeConfig struct {
 	DefaultCurrency string `json:"default_currency"`
 }
 
+// DefaultCapAdmin — имя администратора, заданного полями cap_username/cap_password.
+const DefaultCapAdmin = "default"
+
+type CapAdminConfig struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
 type HTTPConfig struct {
-	CapURL      string `json:"cap_url"`
-	PublicURL   string `json:"public_url"`
-	Timeout     int    `json:"timeout"`
-	CapUsername string `json:"cap_username"`
-	CapPassword string `json:"cap_password"`
+	CapURL        string                    `json:"cap_url" required:"true"`
+	PublicURL     string                    `json:"public_url" required:"true"`
+	Timeout       time.Duration             `json:"timeout" unit:"s"`
+	CapUsername   string                    `json:"cap_username"`
+	CapPassword   string                    `json:"cap_password"`
+	CapAdmins     map[string]CapAdminConfig `json:"cap_admins"`
+	RetryAttempts int                       `json:"retry_attempts"`
+	RetryDelay    time.Duration             `json:"retry_delay" unit:"s"`
 }
 
 type RedisConfig struct {
-	PlayerAddr    string        `json:"player_addr"`
-	WalletAddr    string        `json:"wallet_addr"`
+	PlayerAddr    string        `json:"player_addr" required:"true"`
+	WalletAddr    string        `json:"wallet_addr" required:"true"`
 	Password      string        `json:"password"`
 	PlayerDB      int           `json:"player_db"`
 	WalletDB      int           `json:"wallet_db"`
-	DialTimeout   time.Duration `json:"dial_timeout"`
-	ReadTimeout   time.Duration `json:"read_timeout"`
-	WriteTimeout  time.Duration `json:"write_timeout"`
+	DialTimeout   time.Duration `json:"dial_timeout" unit:"s"`
+	ReadTimeout   time.Duration `json:"read_timeout" unit:"s"`
+	WriteTimeout  time.Duration `json:"write_timeout" unit:"s"`
 	RetryAttempts int           `json:"retryAttempts"`
-	RetryDelay    time.Duration `json:"retryDelay"`
+	RetryDelay    time.Duration `json:"retryDelay" unit:"s"`
+	MaxRetryDelay time.Duration `json:"maxRetryDelay" unit:"s"`
+	WaitTimeout   time.Duration `json:"waitTimeout" unit:"s"`
+	Notifications bool          `json:"notifications"`
 }
 
 type Config struct {
@@ -84,6 +132,8 @@ type Config struct {
 	Kafka KafkaConfig `json:"kafka"`
 	Nats  NatsConfig  `json:"nats"`
 	Redis RedisConfig `json:"redis"`
+
+	Redaction redact.Rules `json:"redaction"`
 }
 
 func (k *KafkaConfig) GetTimeout() time.Duration {
@@ -102,23 +152,19 @@ func ReadConfig(t provider.T) *Config {
 		t.Fatalf("Ошибка установки пути для отчетов Allure: %v", err)
 	}
 
-	configPath := filepath.Join(projectRoot, "config.json")
-	configFile, err := os.Open(configPath)
-	if err != nil {
-		t.Fatalf("Ошибка открытия файла конфигурации: %v", err)
+	configDir := projectRoot
+	if dir := os.Getenv(DirEnv); dir != "" {
+		configDir = dir
 	}
-	defer configFile.Close()
 
-	var config Config
-	decoder := json.NewDecoder(configFile)
-	if err := decoder.Decode(&config); err != nil {
-		t.Fatalf("Ошибка декодирования конфигурации: %v", err)
+	config, err := Load(configDir, SelectedProfile())
+	if err != nil {
+		t.Fatalf("Ошибка загрузки конфигурации:\n%v", err)
 	}
 
-	config.MySQL.PingTimeout *= time.Nanosecond
-	config.MySQL.ConnMaxLifetime *= time.Nanosecond
-	config.MySQL.ConnMaxIdleTime *= time.Nanosecond
-	config.Kafka.Timeout *= time.Second
+	if err := redact.Configure(config.Redaction); err != nil {
+		t.Fatalf("Ошибка настройки скрытия секретов: %v", err)
+	}
 
-	return &config
+	return config
 }
@@ -0,0 +1,284 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"CB_auto/pkg/redact"
+)
+
+const (
+	// ProfileEnv выбирает профиль окружения (config.<profile>.json), например beta-09 или stage.
+	ProfileEnv = "CB_PROFILE"
+	// ProfileFlag — флаг с тем же назначением, например go test ./test/... -args -cb.profile=stage.
+	ProfileFlag = "cb.profile"
+	// DirEnv задает каталог с файлами конфигурации вместо корня репозитория.
+	DirEnv = "CB_CONFIG_DIR"
+
+	envPrefix = "CB"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Флаг регистрируется при инициализации пакета, чтобы go test не отвергал его при разборе аргументов.
+func init() {
+	if flag.Lookup(ProfileFlag) == nil {
+		flag.String(ProfileFlag, "", "профиль окружения (config.<profile>.json), приоритетнее "+ProfileEnv)
+	}
+}
+
+// SelectedProfile возвращает профиль из флага -cb.profile, а если он не задан — из переменной CB_PROFILE.
+func SelectedProfile() string {
+	if f := flag.Lookup(ProfileFlag); f != nil && f.Value.String() != "" {
+		return f.Value.String()
+	}
+	return os.Getenv(ProfileEnv)
+}
+
+// Load собирает конфигурацию слоями: config.json, затем config.<profile>.json, затем переменные окружения
+// вида CB_<SECTION>_<FIELD>, имена которых строятся из json-тегов (например, CB_HTTP_CAP_URL, CB_MYSQL_COMMON_HOST).
+// Длительности задаются строкой ("30s", "500ms") или числом в единицах тега unit.
+// Поля-словари (cap_admins, streams, decoders) переопределяются целиком JSON-объектом,
+// например CB_NATS_STREAMS='{"wallet":"beta-09_wallet"}'.
+// Все ошибки разбора и валидации возвращаются одной ошибкой.
+func Load(dir, profile string) (*Config, error) {
+	files := []string{filepath.Join(dir, "config.json")}
+	if profile != "" {
+		files = append(files, filepath.Join(dir, fmt.Sprintf("config.%s.json", profile)))
+	}
+
+	merged := make(map[string]any)
+	var errs []error
+	for i, path := range files {
+		layer, err := readLayer(path)
+		if err != nil {
+			if i == 0 && profile != "" && errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			errs = append(errs, err)
+			continue
+		}
+		mergeLayers(merged, layer)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	var cfg Config
+	decodeStruct(reflect.ValueOf(&cfg).Elem(), merged, "", envPrefix, &errs)
+	validateStruct(reflect.ValueOf(cfg), "", &errs)
+	validateConfig(&cfg, &errs)
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return &cfg, nil
+}
+
+func readLayer(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("чтение %s: %w", path, err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var layer map[string]any
+	if err := decoder.Decode(&layer); err != nil {
+		return nil, fmt.Errorf("разбор %s: %w", path, err)
+	}
+	return layer, nil
+}
+
+func mergeLayers(dst, src map[string]any) {
+	for key, value := range src {
+		srcMap, srcIsMap := value.(map[string]any)
+		dstMap, dstIsMap := dst[key].(map[string]any)
+		if srcIsMap && dstIsMap {
+			mergeLayers(dstMap, srcMap)
+			continue
+		}
+		dst[key] = value
+	}
+}
+
+func jsonName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func decodeStruct(v reflect.Value, raw map[string]any, path, envName string, errs *[]error) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := jsonName(field)
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+
+		fieldPath := joinPath(path, name)
+		fieldEnv := envName + "_" + strings.ToUpper(name)
+		value, present := raw[name]
+
+		if field.Type.Kind() == reflect.Struct && field.Type != durationType {
+			nested, ok := value.(map[string]any)
+			if present && !ok {
+				*errs = append(*errs, fmt.Errorf("%s: ожидается объект", fieldPath))
+			}
+			decodeStruct(v.Field(i), nested, fieldPath, fieldEnv, errs)
+			continue
+		}
+
+		if envValue, ok := os.LookupEnv(fieldEnv); ok {
+			value, present = envValue, true
+		}
+		if !present {
+			continue
+		}
+		if err := assign(v.Field(i), value, field.Tag.Get("unit")); err != nil {
+			*errs = append(*errs, fmt.Errorf("%s: %v", fieldPath, err))
+		}
+	}
+}
+
+func assign(v reflect.Value, value any, unit string) error {
+	if v.Type() == durationType {
+		d, err := parseDuration(value, unit)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		switch val := value.(type) {
+		case string:
+			v.SetString(val)
+		case json.Number:
+			v.SetString(val.String())
+		default:
+			return fmt.Errorf("ожидается строка, получено %T", value)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(fmt.Sprint(value), 10, 64)
+		if err != nil {
+			return fmt.Errorf("ожидается целое число, получено %v", value)
+		}
+		v.SetInt(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(fmt.Sprint(value))
+		if err != nil {
+			return fmt.Errorf("ожидается true или false, получено %v", value)
+		}
+		v.SetBool(b)
+	case reflect.Map:
+		if s, ok := value.(string); ok {
+			v.Set(reflect.Zero(v.Type()))
+			if err := json.Unmarshal([]byte(s), v.Addr().Interface()); err != nil {
+				return fmt.Errorf("ожидается JSON-объект: %v", err)
+			}
+			return nil
+		}
+		return assignJSON(v, value)
+	case reflect.Slice:
+		if s, ok := value.(string); ok && v.Type().Elem().Kind() == reflect.String {
+			items := strings.Split(s, ",")
+			for i := range items {
+				items[i] = strings.TrimSpace(items[i])
+			}
+			value = items
+		}
+		return assignJSON(v, value)
+	default:
+		return assignJSON(v, value)
+	}
+	return nil
+}
+
+func assignJSON(v reflect.Value, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v.Addr().Interface())
+}
+
+// parseDuration принимает строку формата time.ParseDuration или число в единицах unit (по умолчанию наносекунды).
+func parseDuration(value any, unit string) (time.Duration, error) {
+	raw := fmt.Sprint(value)
+	if n, err := strconv.ParseFloat(raw, 64); err == nil {
+		multiplier, ok := durationUnits[unit]
+		if !ok {
+			return 0, fmt.Errorf("неизвестная единица длительности %q", unit)
+		}
+		return time.Duration(n * float64(multiplier)), nil
+	}
+
+	d, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, fmt.Errorf("некорректная длительность %q", raw)
+	}
+	return d, nil
+}
+
+var durationUnits = map[string]time.Duration{
+	"":   time.Nanosecond,
+	"ns": time.Nanosecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+}
+
+// validateStruct проверяет поля с тегом required:"true" и отрицательные длительности.
+func validateStruct(v reflect.Value, path string, errs *[]error) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fieldPath := joinPath(path, jsonName(field))
+		value := v.Field(i)
+
+		if field.Type.Kind() == reflect.Struct && field.Type != durationType {
+			validateStruct(value, fieldPath, errs)
+			continue
+		}
+		if field.Tag.Get("required") == "true" && value.IsZero() {
+			*errs = append(*errs, fmt.Errorf("%s: обязательное поле не задано", fieldPath))
+		}
+		if field.Type == durationType && value.Int() < 0 {
+			*errs = append(*errs, fmt.Errorf("%s: длительность не может быть отрицательной", fieldPath))
+		}
+	}
+}
+
+// validateConfig проверяет ограничения, которые не выражаются тегами полей.
+func validateConfig(cfg *Config, errs *[]error) {
+	if _, err := redact.New(cfg.Redaction); err != nil {
+		*errs = append(*errs, fmt.Errorf("redaction: %v", err))
+	}
+	if _, ok := cfg.HTTP.CapAdmins[DefaultCapAdmin]; ok {
+		*errs = append(*errs, fmt.Errorf("http.cap_admins.%s: имя зарезервировано за администратором из cap_username/cap_password", DefaultCapAdmin))
+	}
+}
@@ -0,0 +1,90 @@
+package bonus
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+
+	"CB_auto/internal/config"
+	"CB_auto/internal/repository"
+	"CB_auto/pkg/utils"
+
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/shopspring/decimal"
+)
+
+type TransferType int
+
+// Bonus — настройки бонуса. Кошелек бонусного типа копирует их в WalletFullData.BonusInfo.
+type Bonus struct {
+	UUID          string              `db:"uuid"`
+	NodeUUID      string              `db:"node_uuid"`
+	Category      string              `db:"category"`
+	Wager         decimal.Decimal     `db:"wager"`
+	Threshold     decimal.NullDecimal `db:"threshold"`
+	TransferType  TransferType        `db:"transfer_type"`
+	TransferValue decimal.Decimal     `db:"transfer_value"`
+	RealPercent   int                 `db:"real_percent"`
+	BonusPercent  int                 `db:"bonus_percent"`
+	BetMin        decimal.NullDecimal `db:"bet_min"`
+	BetMax        decimal.NullDecimal `db:"bet_max"`
+}
+
+type BonusRepository struct {
+	db  *sql.DB
+	cfg *config.MySQLConfig
+}
+
+func NewBonusRepository(db *sql.DB, mysqlConfig *config.MySQLConfig) *BonusRepository {
+	return &BonusRepository{
+		db:  db,
+		cfg: mysqlConfig,
+	}
+}
+
+var bonusAllowedFields = repository.Columns{
+	"uuid":          true,
+	"node_uuid":     true,
+	"category":      true,
+	"transfer_type": true,
+}
+
+const bonusSelectQuery = `SELECT
+		uuid,
+		node_uuid,
+		category,
+		wager,
+		threshold,
+		transfer_type,
+		transfer_value,
+		real_percent,
+		bonus_percent,
+		bet_min,
+		bet_max
+	FROM bonus`
+
+func (r *BonusRepository) GetBonus(sCtx provider.StepCtx, bonusUUID string) (*Bonus, error) {
+	q := repository.NewQuery(repository.Eq("uuid", bonusUUID))
+	bonus, err := repository.GetOne[Bonus](sCtx, r.db, r.cfg, bonusSelectQuery, bonusAllowedFields, q)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Printf("Нет данных бонуса, возвращаем nil: %v", err)
+			return nil, nil
+		}
+		log.Printf("Ошибка при получении данных бонуса: %v", err)
+		return nil, err
+	}
+	sCtx.WithAttachments(allure.NewAttachment("Bonus DB Data", allure.JSON, utils.CreatePrettyJSON(bonus)))
+	return bonus, nil
+}
+
+func (r *BonusRepository) ListBonuses(sCtx provider.StepCtx, q repository.Query) ([]Bonus, error) {
+	bonuses, err := repository.List[Bonus](sCtx, r.db, r.cfg, bonusSelectQuery, bonusAllowedFields, q)
+	if err != nil {
+		log.Printf("Ошибка при получении списка бонусов: %v", err)
+		return nil, err
+	}
+	repository.AttachRows(sCtx, "Bonuses DB Data", bonuses)
+	return bonuses, nil
+}
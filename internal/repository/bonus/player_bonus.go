@@ -0,0 +1,79 @@
+package bonus
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+
+	"CB_auto/internal/config"
+	"CB_auto/internal/repository"
+	"CB_auto/pkg/utils"
+
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+)
+
+// PlayerBonus — бонус, выданный игроку. Колонки повторяют поля бонусного кошелька в WalletFullData:
+// UUID совпадает с PlayerBonusUUID, BonusUUID — с BonusInfo.BonusUUID.
+type PlayerBonus struct {
+	UUID       string `db:"uuid"`
+	BonusUUID  string `db:"bonus_uuid"`
+	PlayerUUID string `db:"player_uuid"`
+	WalletUUID string `db:"wallet_uuid"`
+	NodeUUID   string `db:"node_uuid"`
+}
+
+type PlayerBonusRepository struct {
+	db  *sql.DB
+	cfg *config.MySQLConfig
+}
+
+func NewPlayerBonusRepository(db *sql.DB, mysqlConfig *config.MySQLConfig) *PlayerBonusRepository {
+	return &PlayerBonusRepository{
+		db:  db,
+		cfg: mysqlConfig,
+	}
+}
+
+var playerBonusAllowedFields = repository.Columns{
+	"uuid":        true,
+	"bonus_uuid":  true,
+	"player_uuid": true,
+	"wallet_uuid": true,
+	"node_uuid":   true,
+}
+
+const playerBonusSelectQuery = `SELECT
+		uuid,
+		bonus_uuid,
+		player_uuid,
+		wallet_uuid,
+		node_uuid
+	FROM player_bonus`
+
+func (r *PlayerBonusRepository) GetPlayerBonus(sCtx provider.StepCtx, playerBonusUUID string) (*PlayerBonus, error) {
+	q := repository.NewQuery(repository.Eq("uuid", playerBonusUUID))
+	playerBonus, err := repository.GetOne[PlayerBonus](sCtx, r.db, r.cfg, playerBonusSelectQuery, playerBonusAllowedFields, q)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Printf("Нет данных бонуса игрока, возвращаем nil: %v", err)
+			return nil, nil
+		}
+		log.Printf("Ошибка при получении данных бонуса игрока: %v", err)
+		return nil, err
+	}
+	sCtx.WithAttachments(allure.NewAttachment("Player Bonus DB Data", allure.JSON, utils.CreatePrettyJSON(playerBonus)))
+	return playerBonus, nil
+}
+
+// ListPlayerBonuses возвращает бонусы игрока, например:
+// repository.NewQuery(repository.Eq("player_uuid", uuid)).
+func (r *PlayerBonusRepository) ListPlayerBonuses(sCtx provider.StepCtx, q repository.Query) ([]PlayerBonus, error) {
+	playerBonuses, err := repository.List[PlayerBonus](sCtx, r.db, r.cfg, playerBonusSelectQuery, playerBonusAllowedFields, q)
+	if err != nil {
+		log.Printf("Ошибка при получении списка бонусов игрока: %v", err)
+		return nil, err
+	}
+	repository.AttachRows(sCtx, "Player Bonuses DB Data", playerBonuses)
+	return playerBonuses, nil
+}
@@ -0,0 +1,77 @@
+package bonus
+
+import (
+	"database/sql"
+	"log"
+
+	"CB_auto/internal/config"
+	"CB_auto/internal/repository"
+
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/shopspring/decimal"
+)
+
+// BonusTransfer — перевод средств бонусного кошелька на реальный после отыгрыша.
+// Сумма делится между реальной и бонусной частью по RealPercent и BonusPercent бонуса.
+type BonusTransfer struct {
+	UUID            string          `db:"uuid"`
+	PlayerBonusUUID string          `db:"player_bonus_uuid"`
+	WalletUUID      string          `db:"wallet_uuid"`
+	TransferType    TransferType    `db:"transfer_type"`
+	Amount          decimal.Decimal `db:"amount"`
+	RealAmount      decimal.Decimal `db:"real_amount"`
+	BonusAmount     decimal.Decimal `db:"bonus_amount"`
+	Status          int             `db:"status"`
+	CreatedAt       int64           `db:"created_at"`
+}
+
+type TransferRepository struct {
+	db  *sql.DB
+	cfg *config.MySQLConfig
+}
+
+func NewTransferRepository(db *sql.DB, mysqlConfig *config.MySQLConfig) *TransferRepository {
+	return &TransferRepository{
+		db:  db,
+		cfg: mysqlConfig,
+	}
+}
+
+const bonusTransferTable = "bonus_transfer"
+
+var transferAllowedFields = repository.Columns{
+	"uuid":              true,
+	"player_bonus_uuid": true,
+	"wallet_uuid":       true,
+	"transfer_type":     true,
+	"status":            true,
+	"created_at":        true,
+}
+
+const transferSelectQuery = `SELECT
+		uuid,
+		player_bonus_uuid,
+		wallet_uuid,
+		transfer_type,
+		amount,
+		real_amount,
+		bonus_amount,
+		status,
+		created_at
+	FROM bonus_transfer`
+
+// ListTransfers возвращает переводы по бонусу игрока в порядке создания.
+func (r *TransferRepository) ListTransfers(sCtx provider.StepCtx, playerBonusUUID string) ([]BonusTransfer, error) {
+	q := repository.NewQuery(repository.Eq("player_bonus_uuid", playerBonusUUID)).OrderBy("created_at")
+	transfers, err := repository.List[BonusTransfer](sCtx, r.db, r.cfg, transferSelectQuery, transferAllowedFields, q)
+	if err != nil {
+		log.Printf("Ошибка при получении переводов бонуса: %v", err)
+		return nil, err
+	}
+	repository.AttachRows(sCtx, "Bonus Transfers DB Data", transfers)
+	return transfers, nil
+}
+
+func (r *TransferRepository) CountTransfers(sCtx provider.StepCtx, q repository.Query) (int, error) {
+	return repository.Count(sCtx, r.db, r.cfg, bonusTransferTable, transferAllowedFields, q)
+}
@@ -0,0 +1,86 @@
+package bonus
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+
+	"CB_auto/internal/config"
+	"CB_auto/internal/repository"
+	"CB_auto/pkg/utils"
+
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/shopspring/decimal"
+)
+
+// WageringProgress — прогресс отыгрыша бонуса игрока: сколько нужно поставить и сколько уже поставлено.
+type WageringProgress struct {
+	PlayerBonusUUID string               `db:"player_bonus_uuid"`
+	WagerAmount     decimal.Decimal      `db:"wager_amount"`
+	WageredAmount   decimal.Decimal      `db:"wagered_amount"`
+	Currency        string               `db:"currency"`
+	UpdatedAt       repository.NullInt64 `db:"updated_at"`
+}
+
+// Rest возвращает остаток суммы, которую нужно поставить для отыгрыша.
+func (w WageringProgress) Rest() decimal.Decimal {
+	rest := w.WagerAmount.Sub(w.WageredAmount)
+	if rest.IsNegative() {
+		return decimal.Zero
+	}
+	return rest
+}
+
+type WageringRepository struct {
+	db  *sql.DB
+	cfg *config.MySQLConfig
+}
+
+func NewWageringRepository(db *sql.DB, mysqlConfig *config.MySQLConfig) *WageringRepository {
+	return &WageringRepository{
+		db:  db,
+		cfg: mysqlConfig,
+	}
+}
+
+var wageringAllowedFields = repository.Columns{
+	"player_bonus_uuid": true,
+	"wagered_amount":    true,
+	"updated_at":        true,
+}
+
+const wageringSelectQuery = `SELECT
+		player_bonus_uuid,
+		wager_amount,
+		wagered_amount,
+		currency,
+		updated_at
+	FROM player_bonus_wagering`
+
+func (r *WageringRepository) GetWageringProgress(sCtx provider.StepCtx, playerBonusUUID string) (*WageringProgress, error) {
+	return r.fetchWageringProgress(sCtx, repository.NewQuery(repository.Eq("player_bonus_uuid", playerBonusUUID)))
+}
+
+// WaitForWagered ждет, пока поставленная сумма отыгрыша не достигнет wagered. Прогресс обновляется
+// асинхронно после ставок, поэтому запрос повторяется до retry_timeout.
+func (r *WageringRepository) WaitForWagered(sCtx provider.StepCtx, playerBonusUUID string, wagered decimal.Decimal) (*WageringProgress, error) {
+	return r.fetchWageringProgress(sCtx, repository.NewQuery(
+		repository.Eq("player_bonus_uuid", playerBonusUUID),
+		repository.Gte("wagered_amount", wagered),
+	))
+}
+
+func (r *WageringRepository) fetchWageringProgress(sCtx provider.StepCtx, q repository.Query) (*WageringProgress, error) {
+	progress, err := repository.GetOne[WageringProgress](sCtx, r.db, r.cfg, wageringSelectQuery, wageringAllowedFields, q)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Printf("Нет данных отыгрыша бонуса, возвращаем nil: %v", err)
+			return nil, nil
+		}
+		log.Printf("Ошибка при получении прогресса отыгрыша: %v", err)
+		return nil, err
+	}
+	sCtx.WithAttachments(allure.NewAttachment("Wagering Progress DB Data", allure.JSON, utils.CreatePrettyJSON(progress)))
+	return progress, nil
+}
@@ -4,9 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"log"
-	"strings"
 	"time"
 
 	"CB_auto/internal/config"
@@ -41,7 +40,7 @@ func NewRepository(db *sql.DB, mysqlConfig *config.MySQLConfig) *Repository {
 	}
 }
 
-var allowedFields = map[string]bool{
+var allowedFields = repository.Columns{
 	"uuid":   true,
 	"alias":  true,
 	"status": true,
@@ -63,21 +62,10 @@ func (r *Repository) fetchBrand(sCtx provider.StepCtx, filters map[string]interf
 		created_at,
 		updated_at
 	FROM brand`
-	var conditions []string
-	var args []interface{}
-
-	if len(filters) > 0 {
-		for key, value := range filters {
-			if !allowedFields[key] {
-				log.Printf("Недопустимое поле для фильтрации: %s", key)
-				continue
-			}
-			conditions = append(conditions, fmt.Sprintf("%s = ?", key))
-			args = append(args, value)
-		}
-		if len(conditions) > 0 {
-			query += " WHERE " + strings.Join(conditions, " AND ")
-		}
+	query, args, err := repository.FromMap(filters).Build(query, allowedFields)
+	if err != nil {
+		log.Printf("Ошибка построения запроса: %v", err)
+		return nil, err
 	}
 	log.Printf("Executing query: %s with args: %v", query, args)
 	log.Printf("Using database: %v", r.db.Stats())
@@ -102,14 +90,13 @@ func (r *Repository) fetchBrand(sCtx provider.StepCtx, filters map[string]interf
 		)
 	}
 
-	var err error
 	if withRetry {
 		err = repository.ExecuteWithRetry(sCtx, r.cfg, execQuery)
 	} else {
 		err = execQuery(context.Background())
 	}
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, sql.ErrNoRows) {
 			log.Printf("Нет данных бренда, возвращаем nil: %v", err)
 			return nil, nil
 		}
@@ -4,9 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"log"
-	"strings"
 
 	"CB_auto/internal/config"
 	"CB_auto/internal/repository"
@@ -44,7 +43,7 @@ func NewRepository(db *sql.DB, mysqlConfig *config.MySQLConfig) *Repository {
 	}
 }
 
-var allowedFields = map[string]bool{
+var allowedFields = repository.Columns{
 	"uuid":      true,
 	"alias":     true,
 	"status_id": true,
@@ -71,21 +70,10 @@ func (r *Repository) fetchCategory(sCtx provider.StepCtx, filters map[string]int
 		type,
 		cms
 	FROM game_category`
-	var conditions []string
-	var args []interface{}
-
-	if len(filters) > 0 {
-		for key, value := range filters {
-			if !allowedFields[key] {
-				log.Printf("Недопустимое поле для фильтрации: %s", key)
-				continue
-			}
-			conditions = append(conditions, fmt.Sprintf("%s = ?", key))
-			args = append(args, value)
-		}
-		if len(conditions) > 0 {
-			query += " WHERE " + strings.Join(conditions, " AND ")
-		}
+	query, args, err := repository.FromMap(filters).Build(query, allowedFields)
+	if err != nil {
+		log.Printf("Ошибка построения запроса: %v", err)
+		return nil, err
 	}
 	log.Printf("Executing query: %s with args: %v", query, args)
 	log.Printf("Using database: %v", r.db.Stats())
@@ -111,7 +99,6 @@ func (r *Repository) fetchCategory(sCtx provider.StepCtx, filters map[string]int
 		)
 	}
 
-	var err error
 	if withRetry {
 		err = repository.ExecuteWithRetry(sCtx, r.cfg, execQuery)
 	} else {
@@ -141,7 +128,7 @@ func (r *Repository) GetCategoryWithRetry(sCtx provider.StepCtx, filters map[str
 func (r *Repository) GetCategory(sCtx provider.StepCtx, filters map[string]interface{}) (*Category, error) {
 	category, err := r.fetchCategory(sCtx, filters, false)
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, sql.ErrNoRows) {
 			log.Printf("Нет данных категории, возвращаем nil: %v", err)
 			return nil, nil
 		}
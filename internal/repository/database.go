@@ -12,33 +12,6 @@ import (
 	"github.com/ozontech/allure-go/pkg/framework/provider"
 )
 
-func ExecuteWithRetry(sCtx provider.StepCtx, cfg *config.MySQLConfig, operation func(ctx context.Context) error) error {
-	ctx := context.Background()
-	delay := time.Duration(cfg.RetryDelay) * time.Second
-	log.Printf("Starting database operation with %d attempts and %v delay", cfg.RetryAttempts, delay)
-	var lastErr error
-	for attempt := 0; attempt < cfg.RetryAttempts; attempt++ {
-		if err := operation(ctx); err == nil {
-			log.Printf("Database operation succeeded on attempt %d", attempt+1)
-			return nil
-		} else if err == sql.ErrNoRows {
-			lastErr = err
-			log.Printf("No rows found, attempt %d/%d", attempt+1, cfg.RetryAttempts)
-			sCtx.Fail()
-		} else {
-			lastErr = err
-			log.Printf("Database operation failed, attempt %d/%d: %v", attempt+1, cfg.RetryAttempts, err)
-			sCtx.Fail()
-		}
-
-		if attempt < cfg.RetryAttempts-1 {
-			log.Printf("Waiting %v before next attempt", delay)
-			time.Sleep(delay)
-		}
-	}
-	return fmt.Errorf("operation failed after %d attempts: %w", cfg.RetryAttempts, lastErr)
-}
-
 type Connector struct {
 	db *sqlx.DB
 }
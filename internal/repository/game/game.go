@@ -11,9 +11,10 @@ import (
 )
 
 type Game struct {
-	UUID  string `db:"uuid"`
-	Alias string `db:"alias"`
-	Name  string `db:"name"`
+	UUID         string `db:"uuid"`
+	Alias        string `db:"alias"`
+	Name         string `db:"name"`
+	HasFreeSpins bool   `db:"has_free_spins"`
 }
 
 type Repository struct {
@@ -28,27 +29,29 @@ func NewRepository(db *sql.DB, mysqlConfig *config.MySQLConfig) *Repository {
 	}
 }
 
+var allowedFields = repository.Columns{
+	"uuid":           true,
+	"alias":          true,
+	"name":           true,
+	"has_free_spins": true,
+}
+
 func (r *Repository) GetGame(sCtx provider.StepCtx, filters map[string]interface{}) *Game {
 	var game Game
-	var whereClause string
-	var args []interface{}
 
-	for key, value := range filters {
-		if whereClause != "" {
-			whereClause += " AND "
-		}
-		whereClause += key + " = ?"
-		args = append(args, value)
+	query, args, err := repository.FromMap(filters).Build("SELECT uuid, alias, name, has_free_spins FROM game", allowedFields)
+	if err != nil {
+		sCtx.Errorf("Failed to build game query: %v", err)
+		return nil
 	}
 
-	query := "SELECT uuid, alias, name FROM game WHERE " + whereClause
-
-	err := repository.ExecuteWithRetry(sCtx, r.cfg, func(ctx context.Context) error {
-		row := r.db.QueryRow(query, args...)
+	err = repository.ExecuteWithRetry(sCtx, r.cfg, func(ctx context.Context) error {
+		row := r.db.QueryRowContext(ctx, query, args...)
 		err := row.Scan(
 			&game.UUID,
 			&game.Alias,
 			&game.Name,
+			&game.HasFreeSpins,
 		)
 		if err != nil {
 			if err == sql.ErrNoRows {
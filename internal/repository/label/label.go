@@ -3,9 +3,8 @@ package label
 import (
 	"context"
 	"database/sql"
-	"fmt"
+	"errors"
 	"log"
-	"strings"
 
 	"CB_auto/internal/config"
 	"CB_auto/internal/repository"
@@ -40,7 +39,7 @@ func NewRepository(db *sql.DB, mysqlConfig *config.MySQLConfig) *Repository {
 	}
 }
 
-var allowedFields = map[string]bool{
+var allowedFields = repository.Columns{
 	"uuid":            true,
 	"color":           true,
 	"node":            true,
@@ -65,20 +64,10 @@ func (r *Repository) fetchLabel(sCtx provider.StepCtx, filters map[string]interf
 		author_editing,
 		description
 	FROM label`
-	var conditions []string
-	var args []interface{}
-	if len(filters) > 0 {
-		for key, value := range filters {
-			if !allowedFields[key] {
-				log.Printf("Недопустимое поле для фильтрации: %s", key)
-				continue
-			}
-			conditions = append(conditions, fmt.Sprintf("%s = ?", key))
-			args = append(args, value)
-		}
-		if len(conditions) > 0 {
-			query += " WHERE " + strings.Join(conditions, " AND ")
-		}
+	query, args, err := repository.FromMap(filters).Build(query, allowedFields)
+	if err != nil {
+		log.Printf("Ошибка построения запроса: %v", err)
+		return nil, err
 	}
 	log.Printf("Executing query: %s with args: %v", query, args)
 	log.Printf("Using database: %v", r.db.Stats())
@@ -100,14 +89,13 @@ func (r *Repository) fetchLabel(sCtx provider.StepCtx, filters map[string]interf
 		)
 	}
 
-	var err error
 	if withRetry {
 		err = repository.ExecuteWithRetry(sCtx, r.cfg, execQuery)
 	} else {
 		err = execQuery(context.Background())
 	}
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, sql.ErrNoRows) {
 			log.Printf("Нет данных лейбла, возвращаем nil: %v", err)
 			return nil, nil
 		}
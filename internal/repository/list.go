@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+
+	"CB_auto/internal/config"
+	"CB_auto/pkg/utils"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+)
+
+// Page ограничивает выборку страницей number (с 1) размером size.
+func (q Query) Page(number, size int) Query {
+	if number < 1 {
+		number = 1
+	}
+	return q.Limit(size).Offset((number - 1) * size)
+}
+
+// Sqlx оборачивает соединение репозитория в sqlx для сканирования строк в структуры по тегам db.
+func Sqlx(db *sql.DB, cfg *config.MySQLConfig) *sqlx.DB {
+	return sqlx.NewDb(db, cfg.Common.DriverName)
+}
+
+// GetOne выполняет запрос base + q с повторами и сканирует первую строку в T.
+// Если строк нет, возвращает sql.ErrNoRows.
+func GetOne[T any](sCtx provider.StepCtx, db *sql.DB, cfg *config.MySQLConfig, base string, columns Columns, q Query) (*T, error) {
+	query, args, err := q.Build(base, columns)
+	if err != nil {
+		log.Printf("Ошибка построения запроса: %v", err)
+		return nil, err
+	}
+	log.Printf("Executing query: %s with args: %v", query, args)
+
+	var result T
+	err = ExecuteWithRetry(sCtx, cfg, func(ctx context.Context) error {
+		return Sqlx(db, cfg).GetContext(ctx, &result, query, args...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// List выполняет запрос base + q и сканирует все строки в T. Пустой результат не считается ошибкой.
+func List[T any](sCtx provider.StepCtx, db *sql.DB, cfg *config.MySQLConfig, base string, columns Columns, q Query) ([]T, error) {
+	query, args, err := q.Build(base, columns)
+	if err != nil {
+		log.Printf("Ошибка построения запроса: %v", err)
+		return nil, err
+	}
+	log.Printf("Executing query: %s with args: %v", query, args)
+
+	var result []T
+	err = ExecuteWithRetry(sCtx, cfg, func(ctx context.Context) error {
+		result = nil
+		return Sqlx(db, cfg).SelectContext(ctx, &result, query, args...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Count возвращает число строк table, подходящих под условия q. Сортировка и страницы q не учитываются.
+func Count(sCtx provider.StepCtx, db *sql.DB, cfg *config.MySQLConfig, table string, columns Columns, q Query) (int, error) {
+	where, args, err := q.BuildWhere(columns)
+	if err != nil {
+		log.Printf("Ошибка построения запроса: %v", err)
+		return 0, err
+	}
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s%s", table, where)
+	log.Printf("Executing query: %s with args: %v", query, args)
+
+	var count int
+	err = ExecuteWithRetry(sCtx, cfg, func(ctx context.Context) error {
+		return db.QueryRowContext(ctx, query, args...).Scan(&count)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Exists проверяет, есть ли в table хотя бы одна строка, подходящая под условия q.
+func Exists(sCtx provider.StepCtx, db *sql.DB, cfg *config.MySQLConfig, table string, columns Columns, q Query) (bool, error) {
+	where, args, err := q.BuildWhere(columns)
+	if err != nil {
+		log.Printf("Ошибка построения запроса: %v", err)
+		return false, err
+	}
+	query := fmt.Sprintf("SELECT EXISTS(SELECT 1 FROM %s%s)", table, where)
+	log.Printf("Executing query: %s with args: %v", query, args)
+
+	var exists bool
+	err = ExecuteWithRetry(sCtx, cfg, func(ctx context.Context) error {
+		return db.QueryRowContext(ctx, query, args...).Scan(&exists)
+	})
+	if err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+// AttachRows прикладывает выборку к шагу вместе с числом строк в названии.
+func AttachRows[T any](sCtx provider.StepCtx, name string, rows []T) {
+	sCtx.WithAttachments(allure.NewAttachment(fmt.Sprintf("%s (%d)", name, len(rows)), allure.JSON, utils.CreatePrettyJSON(rows)))
+}
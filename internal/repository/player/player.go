@@ -0,0 +1,201 @@
+package player
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+
+	"CB_auto/internal/config"
+	"CB_auto/internal/repository"
+	"CB_auto/pkg/utils"
+
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+)
+
+type ContactType string
+
+const (
+	ContactTypePhone ContactType = "PHONE"
+	ContactTypeEmail ContactType = "EMAIL"
+)
+
+// Player — учетная запись игрока в core-базе. UUID совпадает с Player.ExternalID из Kafka.
+type Player struct {
+	ID        int64                 `db:"id"`
+	UUID      string                `db:"uuid"`
+	AccountID string                `db:"account_id"`
+	NodeUUID  string                `db:"node_uuid"`
+	Country   string                `db:"country"`
+	Currency  string                `db:"currency"`
+	Phone     repository.NullString `db:"phone"`
+	Email     repository.NullString `db:"email"`
+	Locale    string                `db:"locale"`
+	CreatedAt int64                 `db:"created_at"`
+	UpdatedAt repository.NullInt64  `db:"updated_at"`
+}
+
+// Contact — контакт игрока и состояние его подтверждения.
+type Contact struct {
+	PlayerUUID  string               `db:"player_uuid"`
+	Type        ContactType          `db:"type"`
+	Value       string               `db:"value"`
+	IsConfirmed bool                 `db:"is_confirmed"`
+	ConfirmedAt repository.NullInt64 `db:"confirmed_at"`
+}
+
+// Verification — запрос на верификацию документа. UUID совпадает с DocumentID в ответе Public API.
+type Verification struct {
+	UUID           string                `db:"uuid"`
+	PlayerUUID     string                `db:"player_uuid"`
+	Type           string                `db:"type"`
+	DocumentType   string                `db:"document_type"`
+	DocumentNumber repository.NullString `db:"document_number"`
+	Status         int                   `db:"status"`
+	CreatedAt      int64                 `db:"created_at"`
+	UpdatedAt      repository.NullInt64  `db:"updated_at"`
+}
+
+type Repository struct {
+	db  *sql.DB
+	cfg *config.MySQLConfig
+}
+
+func NewRepository(db *sql.DB, mysqlConfig *config.MySQLConfig) *Repository {
+	return &Repository{
+		db:  db,
+		cfg: mysqlConfig,
+	}
+}
+
+var playerAllowedFields = repository.Columns{
+	"uuid":       true,
+	"account_id": true,
+	"node_uuid":  true,
+	"phone":      true,
+	"email":      true,
+	"created_at": true,
+}
+
+var contactAllowedFields = repository.Columns{
+	"player_uuid":  true,
+	"type":         true,
+	"value":        true,
+	"is_confirmed": true,
+	"confirmed_at": true,
+}
+
+var verificationAllowedFields = repository.Columns{
+	"uuid":          true,
+	"player_uuid":   true,
+	"type":          true,
+	"document_type": true,
+	"status":        true,
+	"created_at":    true,
+}
+
+const playerSelectQuery = `SELECT
+		id,
+		uuid,
+		account_id,
+		node_uuid,
+		country,
+		currency,
+		phone,
+		email,
+		locale,
+		created_at,
+		updated_at
+	FROM player`
+
+const contactSelectQuery = `SELECT
+		player_uuid,
+		type,
+		value,
+		is_confirmed,
+		confirmed_at
+	FROM player_contact`
+
+const verificationSelectQuery = `SELECT
+		uuid,
+		player_uuid,
+		type,
+		document_type,
+		document_number,
+		status,
+		created_at,
+		updated_at
+	FROM player_verification`
+
+func (r *Repository) fetchPlayer(sCtx provider.StepCtx, q repository.Query) (*Player, error) {
+	player, err := repository.GetOne[Player](sCtx, r.db, r.cfg, playerSelectQuery, playerAllowedFields, q)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Printf("Нет данных игрока, возвращаем nil: %v", err)
+			return nil, nil
+		}
+		log.Printf("Ошибка при получении данных игрока: %v", err)
+		return nil, err
+	}
+	sCtx.WithAttachments(allure.NewAttachment("Player DB Data", allure.JSON, utils.CreatePrettyJSON(player)))
+	return player, nil
+}
+
+func (r *Repository) GetPlayerByUUID(sCtx provider.StepCtx, playerUUID string) (*Player, error) {
+	return r.fetchPlayer(sCtx, repository.NewQuery(repository.Eq("uuid", playerUUID)))
+}
+
+// GetPlayerByPhone ищет игрока по телефону в том виде, в котором он хранится: без ведущего "+".
+func (r *Repository) GetPlayerByPhone(sCtx provider.StepCtx, phone string) (*Player, error) {
+	return r.fetchPlayer(sCtx, repository.NewQuery(repository.Eq("phone", phone)))
+}
+
+func (r *Repository) GetPlayerByEmail(sCtx provider.StepCtx, email string) (*Player, error) {
+	return r.fetchPlayer(sCtx, repository.NewQuery(repository.Eq("email", email)))
+}
+
+func (r *Repository) ListContacts(sCtx provider.StepCtx, playerUUID string) ([]Contact, error) {
+	q := repository.NewQuery(repository.Eq("player_uuid", playerUUID)).OrderBy("type")
+	contacts, err := repository.List[Contact](sCtx, r.db, r.cfg, contactSelectQuery, contactAllowedFields, q)
+	if err != nil {
+		log.Printf("Ошибка при получении контактов игрока: %v", err)
+		return nil, err
+	}
+	repository.AttachRows(sCtx, "Player Contacts DB Data", contacts)
+	return contacts, nil
+}
+
+// IsContactConfirmed проверяет, что контакт value типа contactType подтвержден игроком.
+func (r *Repository) IsContactConfirmed(sCtx provider.StepCtx, playerUUID string, contactType ContactType, value string) (bool, error) {
+	q := repository.NewQuery(
+		repository.Eq("player_uuid", playerUUID),
+		repository.Eq("type", contactType),
+		repository.Eq("value", value),
+		repository.Eq("is_confirmed", true),
+	)
+	return repository.Exists(sCtx, r.db, r.cfg, "player_contact", contactAllowedFields, q)
+}
+
+func (r *Repository) ListVerifications(sCtx provider.StepCtx, playerUUID string) ([]Verification, error) {
+	q := repository.NewQuery(repository.Eq("player_uuid", playerUUID)).OrderBy("created_at")
+	verifications, err := repository.List[Verification](sCtx, r.db, r.cfg, verificationSelectQuery, verificationAllowedFields, q)
+	if err != nil {
+		log.Printf("Ошибка при получении верификаций игрока: %v", err)
+		return nil, err
+	}
+	repository.AttachRows(sCtx, "Player Verifications DB Data", verifications)
+	return verifications, nil
+}
+
+// GetVerification возвращает верификацию по идентификатору документа, если ее статус уже равен status.
+// Статус обновляется асинхронно, поэтому запрос повторяется до retry_timeout.
+func (r *Repository) GetVerification(sCtx provider.StepCtx, documentID string, status int) (*Verification, error) {
+	q := repository.NewQuery(repository.Eq("uuid", documentID), repository.Eq("status", status))
+	verification, err := repository.GetOne[Verification](sCtx, r.db, r.cfg, verificationSelectQuery, verificationAllowedFields, q)
+	if err != nil {
+		log.Printf("Ошибка при получении верификации: %v", err)
+		return nil, err
+	}
+	sCtx.WithAttachments(allure.NewAttachment("Player Verification DB Data", allure.JSON, utils.CreatePrettyJSON(verification)))
+	return verification, nil
+}
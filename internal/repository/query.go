@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrUnknownColumn    = errors.New("repository: column is not allowed")
+	ErrInvalidCondition = errors.New("repository: invalid condition")
+)
+
+// Columns — колонки таблицы, по которым разрешены фильтрация и сортировка.
+// Имена колонок подставляются в SQL только после проверки по этому списку, значения всегда передаются как параметры.
+type Columns map[string]bool
+
+type Operator string
+
+const (
+	OpEq        Operator = "="
+	OpNotEq     Operator = "<>"
+	OpGt        Operator = ">"
+	OpGte       Operator = ">="
+	OpLt        Operator = "<"
+	OpLte       Operator = "<="
+	OpLike      Operator = "LIKE"
+	OpIn        Operator = "IN"
+	OpIsNull    Operator = "IS NULL"
+	OpIsNotNull Operator = "IS NOT NULL"
+)
+
+type Condition struct {
+	Column   string
+	Operator Operator
+	Value    interface{}
+}
+
+func Eq(column string, value interface{}) Condition {
+	return Condition{Column: column, Operator: OpEq, Value: value}
+}
+
+func NotEq(column string, value interface{}) Condition {
+	return Condition{Column: column, Operator: OpNotEq, Value: value}
+}
+
+func Gt(column string, value interface{}) Condition {
+	return Condition{Column: column, Operator: OpGt, Value: value}
+}
+
+func Gte(column string, value interface{}) Condition {
+	return Condition{Column: column, Operator: OpGte, Value: value}
+}
+
+func Lt(column string, value interface{}) Condition {
+	return Condition{Column: column, Operator: OpLt, Value: value}
+}
+
+func Lte(column string, value interface{}) Condition {
+	return Condition{Column: column, Operator: OpLte, Value: value}
+}
+
+// Like сравнивает колонку с шаблоном, где % и _ — подстановочные символы MySQL.
+func Like(column string, pattern string) Condition {
+	return Condition{Column: column, Operator: OpLike, Value: pattern}
+}
+
+func In(column string, values ...interface{}) Condition {
+	return Condition{Column: column, Operator: OpIn, Value: values}
+}
+
+func IsNull(column string) Condition {
+	return Condition{Column: column, Operator: OpIsNull}
+}
+
+func IsNotNull(column string) Condition {
+	return Condition{Column: column, Operator: OpIsNotNull}
+}
+
+type order struct {
+	column string
+	desc   bool
+}
+
+// Query описывает условия WHERE, сортировку и ограничения выборки. Методы возвращают копию,
+// поэтому базовый запрос можно переиспользовать.
+type Query struct {
+	conditions []Condition
+	orders     []order
+	limit      int
+	offset     int
+}
+
+func NewQuery(conditions ...Condition) Query {
+	return Query{}.Where(conditions...)
+}
+
+// FromMap строит запрос из фильтров вида колонка -> значение с сортировкой по имени колонки,
+// чтобы SQL не зависел от порядка обхода map. Значение nil превращается в IS NULL.
+func FromMap(filters map[string]interface{}) Query {
+	columns := make([]string, 0, len(filters))
+	for column := range filters {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	conditions := make([]Condition, 0, len(columns))
+	for _, column := range columns {
+		if filters[column] == nil {
+			conditions = append(conditions, IsNull(column))
+			continue
+		}
+		conditions = append(conditions, Eq(column, filters[column]))
+	}
+	return NewQuery(conditions...)
+}
+
+// Where добавляет условия, объединяемые через AND.
+func (q Query) Where(conditions ...Condition) Query {
+	q.conditions = append(append([]Condition(nil), q.conditions...), conditions...)
+	return q
+}
+
+func (q Query) OrderBy(column string) Query {
+	q.orders = append(append([]order(nil), q.orders...), order{column: column})
+	return q
+}
+
+func (q Query) OrderByDesc(column string) Query {
+	q.orders = append(append([]order(nil), q.orders...), order{column: column, desc: true})
+	return q
+}
+
+func (q Query) Limit(limit int) Query {
+	q.limit = limit
+	return q
+}
+
+func (q Query) Offset(offset int) Query {
+	q.offset = offset
+	return q
+}
+
+// Build дописывает к base условия, сортировку и ограничения. Колонки, которых нет в columns, приводят к ErrUnknownColumn.
+func (q Query) Build(base string, columns Columns) (string, []interface{}, error) {
+	where, args, err := q.BuildWhere(columns)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var sb strings.Builder
+	sb.WriteString(base)
+	sb.WriteString(where)
+
+	for i, o := range q.orders {
+		if !columns[o.column] {
+			return "", nil, fmt.Errorf("%w: ORDER BY %s", ErrUnknownColumn, o.column)
+		}
+		if i == 0 {
+			sb.WriteString(" ORDER BY ")
+		} else {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(o.column)
+		if o.desc {
+			sb.WriteString(" DESC")
+		}
+	}
+
+	if q.limit < 0 || q.offset < 0 {
+		return "", nil, fmt.Errorf("%w: negative limit or offset", ErrInvalidCondition)
+	}
+	if q.limit > 0 {
+		fmt.Fprintf(&sb, " LIMIT %d", q.limit)
+	}
+	if q.offset > 0 {
+		if q.limit == 0 {
+			return "", nil, fmt.Errorf("%w: offset requires limit", ErrInvalidCondition)
+		}
+		fmt.Fprintf(&sb, " OFFSET %d", q.offset)
+	}
+
+	return sb.String(), args, nil
+}
+
+// BuildWhere возвращает только часть WHERE (с ведущим пробелом) для запросов вида COUNT или EXISTS,
+// где сортировка и ограничения не нужны.
+func (q Query) BuildWhere(columns Columns) (string, []interface{}, error) {
+	if len(q.conditions) == 0 {
+		return "", nil, nil
+	}
+
+	parts := make([]string, 0, len(q.conditions))
+	var args []interface{}
+	for _, c := range q.conditions {
+		if !columns[c.Column] {
+			return "", nil, fmt.Errorf("%w: %s", ErrUnknownColumn, c.Column)
+		}
+
+		switch c.Operator {
+		case OpEq, OpNotEq, OpGt, OpGte, OpLt, OpLte, OpLike:
+			parts = append(parts, fmt.Sprintf("%s %s ?", c.Column, c.Operator))
+			args = append(args, c.Value)
+		case OpIn:
+			values, _ := c.Value.([]interface{})
+			if len(values) == 0 {
+				return "", nil, fmt.Errorf("%w: IN for %s requires at least one value", ErrInvalidCondition, c.Column)
+			}
+			placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ")
+			parts = append(parts, fmt.Sprintf("%s IN (%s)", c.Column, placeholders))
+			args = append(args, values...)
+		case OpIsNull, OpIsNotNull:
+			parts = append(parts, fmt.Sprintf("%s %s", c.Column, c.Operator))
+		default:
+			return "", nil, fmt.Errorf("%w: unsupported operator %q for %s", ErrInvalidCondition, c.Operator, c.Column)
+		}
+	}
+
+	return " WHERE " + strings.Join(parts, " AND "), args, nil
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"time"
+
+	"CB_auto/internal/config"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+)
+
+// retryableMySQLErrors — коды ошибок сервера MySQL, после которых запрос имеет смысл повторить.
+var retryableMySQLErrors = map[uint16]bool{
+	1040: true, // ER_CON_COUNT_ERROR: too many connections
+	1053: true, // ER_SERVER_SHUTDOWN
+	1205: true, // ER_LOCK_WAIT_TIMEOUT
+	1213: true, // ER_LOCK_DEADLOCK
+	1927: true, // ER_CONNECTION_KILLED
+}
+
+// IsRetryable сообщает, может ли повтор запроса дать другой результат.
+// sql.ErrNoRows считается повторяемой: данные в БД появляются асинхронно после событий.
+// Ошибки синтаксиса, схемы, доступа и построения запроса, а также истекший дедлайн повторять бессмысленно.
+func IsRetryable(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, sql.ErrNoRows) ||
+		errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, mysql.ErrInvalidConn) {
+		return true
+	}
+
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return retryableMySQLErrors[mysqlErr.Number]
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+type retryAttempt struct {
+	number    int
+	duration  time.Duration
+	err       error
+	retryable bool
+}
+
+// ExecuteWithRetry выполняет operation, пока она не завершится успешно, не вернет неповторяемую ошибку
+// или не закончатся попытки (retry_attempts) либо общее время (retry_timeout).
+// Дедлайн передается в operation через ctx и ограничивает и саму попытку, и ожидание между попытками.
+// Задержка между попытками удваивается от retry_delay до max_retry_delay.
+// Шаг помечается упавшим только при неудаче последней попытки; история попыток прикладывается к шагу.
+// Если строка так и не появилась (sql.ErrNoRows), шаг не роняется: отсутствие строки оценивает вызывающий.
+func ExecuteWithRetry(sCtx provider.StepCtx, cfg *config.MySQLConfig, operation func(ctx context.Context) error) error {
+	ctx := context.Background()
+	if cfg.RetryTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.RetryTimeout)
+		defer cancel()
+	}
+
+	maxAttempts := max(cfg.RetryAttempts, 1)
+	delay := cfg.RetryDelay
+	maxDelay := cfg.MaxRetryDelay
+	if maxDelay < delay {
+		maxDelay = delay
+	}
+	log.Printf("Starting database operation with %d attempts, %v delay and %v timeout", maxAttempts, delay, cfg.RetryTimeout)
+
+	var attempts []retryAttempt
+	var lastErr error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if ctx.Err() != nil {
+			log.Printf("Database operation deadline %v exceeded", cfg.RetryTimeout)
+			break
+		}
+
+		started := time.Now()
+		err := operation(ctx)
+		if err == nil {
+			log.Printf("Database operation succeeded on attempt %d", attempt)
+			if len(attempts) > 0 {
+				attempts = append(attempts, retryAttempt{number: attempt, duration: time.Since(started)})
+				attachAttempts(sCtx, attempts)
+			}
+			return nil
+		}
+
+		lastErr = err
+		retryable := IsRetryable(err)
+		attempts = append(attempts, retryAttempt{number: attempt, duration: time.Since(started), err: err, retryable: retryable})
+		log.Printf("Database operation failed, attempt %d/%d (retryable: %t): %v", attempt, maxAttempts, retryable, err)
+
+		if !retryable || attempt == maxAttempts {
+			break
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+		delay = min(delay*2, maxDelay)
+	}
+
+	attachAttempts(sCtx, attempts)
+	if !errors.Is(lastErr, sql.ErrNoRows) {
+		sCtx.Fail()
+	}
+	return fmt.Errorf("operation failed after %d attempts: %w", len(attempts), lastErr)
+}
+
+func attachAttempts(sCtx provider.StepCtx, attempts []retryAttempt) {
+	var sb strings.Builder
+	for _, a := range attempts {
+		switch {
+		case a.err == nil:
+			fmt.Fprintf(&sb, "#%d (%v): ok\n", a.number, a.duration.Round(time.Millisecond))
+		case a.retryable:
+			fmt.Fprintf(&sb, "#%d (%v): retryable: %v\n", a.number, a.duration.Round(time.Millisecond), a.err)
+		default:
+			fmt.Fprintf(&sb, "#%d (%v): fatal: %v\n", a.number, a.duration.Round(time.Millisecond), a.err)
+		}
+	}
+	sCtx.WithAttachments(allure.NewAttachment("DB Retry Attempts", allure.Text, []byte(sb.String())))
+}
@@ -1,11 +1,9 @@
 package wallet
 
 import (
-	"context"
 	"database/sql"
-	"fmt"
+	"errors"
 	"log"
-	"strings"
 
 	"CB_auto/internal/config"
 	"CB_auto/internal/repository"
@@ -57,21 +55,20 @@ func NewLimitRecordRepository(db *sql.DB, mysqlConfig *config.MySQLConfig) *Limi
 	}
 }
 
-var allowedFields = map[string]bool{
+var allowedFields = repository.Columns{
 	"external_uuid": true,
 	"player_uuid":   true,
 	"limit_type":    true,
 	"interval_type": true,
 	"currency_code": true,
 	"limit_status":  true,
+	"started_at":    true,
+	"expires_at":    true,
 }
 
-func (r *LimitRecordRepository) fetchLimitRecord(sCtx provider.StepCtx, filters map[string]interface{}) (*LimitRecord, error) {
-	if err := r.db.Ping(); err != nil {
-		log.Printf("Ошибка подключения к БД: %v", err)
-	}
+const limitRecordTable = "limit_record"
 
-	query := `SELECT 
+const limitRecordSelectQuery = `SELECT 
 		external_uuid,
 		player_uuid,
 		limit_type,
@@ -85,41 +82,37 @@ func (r *LimitRecordRepository) fetchLimitRecord(sCtx provider.StepCtx, filters
 		limit_status
 	FROM limit_record`
 
-	var conditions []string
-	var args []interface{}
-	if len(filters) > 0 {
-		for key, value := range filters {
-			if !allowedFields[key] {
-				log.Printf("Недопустимое поле для фильтрации: %s", key)
-			}
-			conditions = append(conditions, fmt.Sprintf("%s = ?", key))
-			args = append(args, value)
-		}
-		query += " WHERE " + strings.Join(conditions, " AND ")
+func (r *LimitRecordRepository) fetchLimitRecord(sCtx provider.StepCtx, filters map[string]interface{}) (*LimitRecord, error) {
+	if err := r.db.Ping(); err != nil {
+		log.Printf("Ошибка подключения к БД: %v", err)
 	}
-	log.Printf("Executing query: %s with args: %v", query, args)
-
-	var limitRecord LimitRecord
-	err := repository.ExecuteWithRetry(sCtx, r.cfg, func(ctx context.Context) error {
-		return r.db.QueryRowContext(ctx, query, args...).Scan(
-			&limitRecord.ExternalUUID,
-			&limitRecord.PlayerUUID,
-			&limitRecord.LimitType,
-			&limitRecord.IntervalType,
-			&limitRecord.Amount,
-			&limitRecord.Spent,
-			&limitRecord.Rest,
-			&limitRecord.CurrencyCode,
-			&limitRecord.StartedAt,
-			&limitRecord.ExpiresAt,
-			&limitRecord.LimitStatus,
-		)
-	})
+
+	limitRecord, err := repository.GetOne[LimitRecord](sCtx, r.db, r.cfg, limitRecordSelectQuery, allowedFields, repository.FromMap(filters))
 	if err != nil {
 		return nil, err
 	}
 	sCtx.WithAttachments(allure.NewAttachment("Limit Record DB Data", allure.JSON, utils.CreatePrettyJSON(limitRecord)))
-	return &limitRecord, nil
+	return limitRecord, nil
+}
+
+// ListLimitRecords возвращает записи лимита за все периоды, например историю лимита:
+// repository.NewQuery(repository.Eq("external_uuid", limitUUID)).OrderBy("started_at").
+func (r *LimitRecordRepository) ListLimitRecords(sCtx provider.StepCtx, q repository.Query) ([]LimitRecord, error) {
+	records, err := repository.List[LimitRecord](sCtx, r.db, r.cfg, limitRecordSelectQuery, allowedFields, q)
+	if err != nil {
+		log.Printf("Ошибка при получении списка лимитов: %v", err)
+		return nil, err
+	}
+	repository.AttachRows(sCtx, "Limit Records DB Data", records)
+	return records, nil
+}
+
+func (r *LimitRecordRepository) CountLimitRecords(sCtx provider.StepCtx, q repository.Query) (int, error) {
+	return repository.Count(sCtx, r.db, r.cfg, limitRecordTable, allowedFields, q)
+}
+
+func (r *LimitRecordRepository) LimitRecordExists(sCtx provider.StepCtx, q repository.Query) (bool, error) {
+	return repository.Exists(sCtx, r.db, r.cfg, limitRecordTable, allowedFields, q)
 }
 
 func (r *LimitRecordRepository) GetLimitRecordWithRetry(sCtx provider.StepCtx, filters map[string]interface{}) *LimitRecord {
@@ -133,7 +126,7 @@ func (r *LimitRecordRepository) GetLimitRecordWithRetry(sCtx provider.StepCtx, f
 func (r *LimitRecordRepository) GetLimitRecord(sCtx provider.StepCtx, filters map[string]interface{}) (*LimitRecord, error) {
 	limitRecord, err := r.fetchLimitRecord(sCtx, filters)
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, sql.ErrNoRows) {
 			log.Printf("Нет данных лимита, возвращаем nil: %v", err)
 			return nil, nil
 		}
@@ -1,11 +1,9 @@
 package wallet
 
 import (
-	"context"
 	"database/sql"
-	"fmt"
+	"errors"
 	"log"
-	"strings"
 
 	"CB_auto/internal/config"
 	"CB_auto/internal/repository"
@@ -34,52 +32,51 @@ func NewPlayerThresholdDepositRepository(db *sql.DB, mysqlConfig *config.MySQLCo
 	}
 }
 
-var playerThresholdDepositAllowedFields = map[string]bool{
+var playerThresholdDepositAllowedFields = repository.Columns{
 	"player_uuid": true,
 	"amount":      true,
 	"updated_at":  true,
 }
 
-func (r *PlayerThresholdDepositRepository) fetchPlayerThresholdDeposit(sCtx provider.StepCtx, filters map[string]interface{}) (*PlayerThresholdDeposit, error) {
-	if err := r.db.Ping(); err != nil {
-		log.Printf("Ошибка подключения к БД: %v", err)
-	}
+const playerThresholdDepositTable = "player_threshold_deposit"
 
-	query := `SELECT 
+const playerThresholdDepositSelectQuery = `SELECT 
 		player_uuid,
 		IF(amount LIKE '%.000000000000000000', 
 		   SUBSTRING_INDEX(amount, '.', 1), 
 		   amount) as amount,
 		updated_at
 	FROM player_threshold_deposit`
-	var conditions []string
-	var args []interface{}
-	if len(filters) > 0 {
-		for key, value := range filters {
-			if !playerThresholdDepositAllowedFields[key] {
-				log.Printf("Недопустимое поле для фильтрации: %s", key)
-				continue
-			}
-			conditions = append(conditions, fmt.Sprintf("%s = ?", key))
-			args = append(args, value)
-		}
-		query += " WHERE " + strings.Join(conditions, " AND ")
+
+func (r *PlayerThresholdDepositRepository) fetchPlayerThresholdDeposit(sCtx provider.StepCtx, filters map[string]interface{}) (*PlayerThresholdDeposit, error) {
+	if err := r.db.Ping(); err != nil {
+		log.Printf("Ошибка подключения к БД: %v", err)
 	}
-	log.Printf("Executing query: %s with args: %v", query, args)
-
-	var record PlayerThresholdDeposit
-	err := repository.ExecuteWithRetry(sCtx, r.cfg, func(ctx context.Context) error {
-		return r.db.QueryRowContext(ctx, query, args...).Scan(
-			&record.PlayerUUID,
-			&record.Amount,
-			&record.UpdatedAt,
-		)
-	})
+
+	record, err := repository.GetOne[PlayerThresholdDeposit](sCtx, r.db, r.cfg, playerThresholdDepositSelectQuery, playerThresholdDepositAllowedFields, repository.FromMap(filters))
 	if err != nil {
 		return nil, err
 	}
 	sCtx.WithAttachments(allure.NewAttachment("PlayerThresholdDeposit DB Data", allure.JSON, utils.CreatePrettyJSON(record)))
-	return &record, nil
+	return record, nil
+}
+
+func (r *PlayerThresholdDepositRepository) ListPlayerThresholdDeposits(sCtx provider.StepCtx, q repository.Query) ([]PlayerThresholdDeposit, error) {
+	records, err := repository.List[PlayerThresholdDeposit](sCtx, r.db, r.cfg, playerThresholdDepositSelectQuery, playerThresholdDepositAllowedFields, q)
+	if err != nil {
+		log.Printf("Ошибка при получении списка порогов депозита: %v", err)
+		return nil, err
+	}
+	repository.AttachRows(sCtx, "PlayerThresholdDeposits DB Data", records)
+	return records, nil
+}
+
+func (r *PlayerThresholdDepositRepository) CountPlayerThresholdDeposits(sCtx provider.StepCtx, q repository.Query) (int, error) {
+	return repository.Count(sCtx, r.db, r.cfg, playerThresholdDepositTable, playerThresholdDepositAllowedFields, q)
+}
+
+func (r *PlayerThresholdDepositRepository) PlayerThresholdDepositExists(sCtx provider.StepCtx, q repository.Query) (bool, error) {
+	return repository.Exists(sCtx, r.db, r.cfg, playerThresholdDepositTable, playerThresholdDepositAllowedFields, q)
 }
 
 func (r *PlayerThresholdDepositRepository) GetPlayerThresholdDepositWithRetry(sCtx provider.StepCtx, filters map[string]interface{}) *PlayerThresholdDeposit {
@@ -93,7 +90,7 @@ func (r *PlayerThresholdDepositRepository) GetPlayerThresholdDepositWithRetry(sC
 func (r *PlayerThresholdDepositRepository) GetPlayerThresholdDeposit(sCtx provider.StepCtx, filters map[string]interface{}) (*PlayerThresholdDeposit, error) {
 	record, err := r.fetchPlayerThresholdDeposit(sCtx, filters)
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, sql.ErrNoRows) {
 			log.Printf("Нет данных порога депозита игрока, возвращаем nil: %v", err)
 			return nil, nil
 		}
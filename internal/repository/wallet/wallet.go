@@ -1,11 +1,9 @@
 package wallet
 
 import (
-	"context"
 	"database/sql"
-	"fmt"
+	"errors"
 	"log"
-	"strings"
 
 	"CB_auto/internal/config"
 	"CB_auto/internal/repository"
@@ -58,7 +56,7 @@ func NewWalletRepository(db *sql.DB, mysqlConfig *config.MySQLConfig) *WalletRep
 	}
 }
 
-var walletAllowedFields = map[string]bool{
+var walletAllowedFields = repository.Columns{
 	"uuid":               true,
 	"player_uuid":        true,
 	"currency":           true,
@@ -69,14 +67,14 @@ var walletAllowedFields = map[string]bool{
 	"wallet_type":        true,
 	"is_gambling_active": true,
 	"is_betting_active":  true,
+	"is_blocked":         true,
+	"seq":                true,
+	"created_at":         true,
 }
 
-func (r *WalletRepository) fetchWallet(sCtx provider.StepCtx, filters map[string]interface{}) (*Wallet, error) {
-	if err := r.db.Ping(); err != nil {
-		log.Printf("Ошибка подключения к БД: %v", err)
-	}
+const walletTable = "wallet"
 
-	query := `SELECT 
+const walletSelectQuery = `SELECT 
 		uuid,
 		player_uuid,
 		currency,
@@ -98,51 +96,39 @@ func (r *WalletRepository) fetchWallet(sCtx provider.StepCtx, filters map[string
 		available_withdrawal,
 		is_kyc_verified
 	FROM wallet`
-	var conditions []string
-	var args []interface{}
-	if len(filters) > 0 {
-		for key, value := range filters {
-			if !allowedFields[key] {
-				log.Printf("Недопустимое поле для фильтрации: %s", key)
-			}
-			conditions = append(conditions, fmt.Sprintf("%s = ?", key))
-			args = append(args, value)
-		}
-		query += " WHERE " + strings.Join(conditions, " AND ")
+
+func (r *WalletRepository) fetchWallet(sCtx provider.StepCtx, filters map[string]interface{}) (*Wallet, error) {
+	if err := r.db.Ping(); err != nil {
+		log.Printf("Ошибка подключения к БД: %v", err)
 	}
-	log.Printf("Executing query: %s with args: %v", query, args)
 	log.Printf("Using database: %v", r.db.Stats())
 
-	var wallet Wallet
-	err := repository.ExecuteWithRetry(sCtx, r.cfg, func(ctx context.Context) error {
-		return r.db.QueryRowContext(ctx, query, args...).Scan(
-			&wallet.UUID,
-			&wallet.PlayerUUID,
-			&wallet.Currency,
-			&wallet.WalletStatus,
-			&wallet.Balance,
-			&wallet.CreatedAt,
-			&wallet.UpdatedAt,
-			&wallet.IsDefault,
-			&wallet.IsBasic,
-			&wallet.IsBlocked,
-			&wallet.WalletType,
-			&wallet.Seq,
-			&wallet.IsGamblingActive,
-			&wallet.IsBettingActive,
-			&wallet.DepositAmount,
-			&wallet.ProfitAmount,
-			&wallet.NodeUUID,
-			&wallet.IsSumsubVerified,
-			&wallet.AvailableWithdrawal,
-			&wallet.IsKycVerified,
-		)
-	})
+	wallet, err := repository.GetOne[Wallet](sCtx, r.db, r.cfg, walletSelectQuery, walletAllowedFields, repository.FromMap(filters))
 	if err != nil {
 		return nil, err
 	}
 	sCtx.WithAttachments(allure.NewAttachment("Wallet DB Data", allure.JSON, utils.CreatePrettyJSON(wallet)))
-	return &wallet, nil
+	return wallet, nil
+}
+
+// ListWallets возвращает все кошельки, подходящие под запрос, например все кошельки игрока:
+// repository.NewQuery(repository.Eq("player_uuid", uuid)).OrderBy("created_at").
+func (r *WalletRepository) ListWallets(sCtx provider.StepCtx, q repository.Query) ([]Wallet, error) {
+	wallets, err := repository.List[Wallet](sCtx, r.db, r.cfg, walletSelectQuery, walletAllowedFields, q)
+	if err != nil {
+		log.Printf("Ошибка при получении списка кошельков: %v", err)
+		return nil, err
+	}
+	repository.AttachRows(sCtx, "Wallets DB Data", wallets)
+	return wallets, nil
+}
+
+func (r *WalletRepository) CountWallets(sCtx provider.StepCtx, q repository.Query) (int, error) {
+	return repository.Count(sCtx, r.db, r.cfg, walletTable, walletAllowedFields, q)
+}
+
+func (r *WalletRepository) WalletExists(sCtx provider.StepCtx, q repository.Query) (bool, error) {
+	return repository.Exists(sCtx, r.db, r.cfg, walletTable, walletAllowedFields, q)
 }
 
 func (r *WalletRepository) GetWalletWithRetry(sCtx provider.StepCtx, filters map[string]interface{}) *Wallet {
@@ -156,7 +142,7 @@ func (r *WalletRepository) GetWalletWithRetry(sCtx provider.StepCtx, filters map
 func (r *WalletRepository) GetWallet(sCtx provider.StepCtx, filters map[string]interface{}) (*Wallet, error) {
 	wallet, err := r.fetchWallet(sCtx, filters)
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, sql.ErrNoRows) {
 			log.Printf("Нет данных кошелька, возвращаем nil: %v", err)
 			return nil, nil
 		}
@@ -0,0 +1,222 @@
+package kafka
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"CB_auto/internal/config"
+
+	"github.com/hamba/avro/v2"
+	"github.com/segmentio/kafka-go"
+)
+
+// SchemaSource возвращает текст схемы по идентификатору из заголовка сообщения.
+type SchemaSource interface {
+	Schema(id int) (string, error)
+}
+
+// RegistrySource читает схемы из Confluent-совместимого schema registry.
+type RegistrySource struct {
+	URL    string
+	Client *http.Client
+}
+
+func (s RegistrySource) Schema(id int) (string, error) {
+	client := s.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	resp, err := client.Get(fmt.Sprintf("%s/schemas/ids/%d", strings.TrimRight(s.URL, "/"), id))
+	if err != nil {
+		return "", fmt.Errorf("schema registry: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("schema registry: schema %d: status %d: %s", id, resp.StatusCode, body)
+	}
+
+	var result struct {
+		Schema string `json:"schema"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("schema registry: schema %d: %w", id, err)
+	}
+	return result.Schema, nil
+}
+
+// DirSource — замена schema registry для офлайн-запусков: схема с идентификатором id лежит в файле <dir>/<id>.avsc.
+type DirSource struct {
+	Dir string
+}
+
+func (s DirSource) Schema(id int) (string, error) {
+	data, err := os.ReadFile(filepath.Join(s.Dir, fmt.Sprintf("%d.avsc", id)))
+	if err != nil {
+		return "", fmt.Errorf("schema dir: %w", err)
+	}
+	return string(data), nil
+}
+
+// AvroDecoder декодирует сообщения в формате schema registry (магический байт, id схемы, Avro binary).
+// Union-значения выводятся без обертки с именем типа, decimal — десятичной строкой без потери точности,
+// timestamp и date — числами, как в бинарной записи, NaN и Infinity — строковыми литералами.
+type AvroDecoder struct {
+	source SchemaSource
+	mu     sync.Mutex
+	cache  map[int]avro.Schema
+}
+
+func NewAvroDecoder(source SchemaSource) *AvroDecoder {
+	return &AvroDecoder{
+		source: source,
+		cache:  make(map[int]avro.Schema),
+	}
+}
+
+func newAvroDecoder(cfg config.KafkaDecoderConfig) (Decoder, error) {
+	switch {
+	case cfg.SchemaDir != "":
+		return NewAvroDecoder(DirSource{Dir: cfg.SchemaDir}), nil
+	case cfg.SchemaRegistryURL != "":
+		return NewAvroDecoder(RegistrySource{URL: cfg.SchemaRegistryURL}), nil
+	default:
+		return nil, fmt.Errorf("kafka: avro decoder requires schema_registry_url or schema_dir")
+	}
+}
+
+func (d *AvroDecoder) Decode(msg kafka.Message) ([]byte, error) {
+	id, payload, err := splitConfluentFrame(msg.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := d.schema(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var value any
+	if err := avro.Unmarshal(schema, payload, &value); err != nil {
+		return nil, fmt.Errorf("avro: schema %d: %w", id, err)
+	}
+	return json.Marshal(jsonSafe(value))
+}
+
+func (d *AvroDecoder) schema(id int) (avro.Schema, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if schema, ok := d.cache[id]; ok {
+		return schema, nil
+	}
+
+	text, err := d.source.Schema(id)
+	if err != nil {
+		return nil, err
+	}
+	text, err = withoutTimeLogicalTypes(text)
+	if err != nil {
+		return nil, fmt.Errorf("avro: parse schema %d: %w", id, err)
+	}
+	// Отдельный кэш имен на каждую схему: версии одной записи из registry не должны подменять друг друга.
+	schema, err := avro.ParseWithCache(text, "", &avro.SchemaCache{})
+	if err != nil {
+		return nil, fmt.Errorf("avro: parse schema %d: %w", id, err)
+	}
+	d.cache[id] = schema
+	return schema, nil
+}
+
+// withoutTimeLogicalTypes убирает из схемы логические типы, кроме decimal, чтобы timestamp, date и time
+// декодировались числами, а не time.Time, и фильтры по int64-полям работали без преобразований.
+func withoutTimeLogicalTypes(text string) (string, error) {
+	var schema any
+	if err := json.Unmarshal([]byte(text), &schema); err != nil {
+		return "", err
+	}
+	var strip func(node any)
+	strip = func(node any) {
+		switch node := node.(type) {
+		case map[string]any:
+			if logicalType, ok := node["logicalType"]; ok && logicalType != "decimal" {
+				delete(node, "logicalType")
+			}
+			for _, child := range node {
+				strip(child)
+			}
+		case []any:
+			for _, child := range node {
+				strip(child)
+			}
+		}
+	}
+	strip(schema)
+
+	data, err := json.Marshal(schema)
+	return string(data), err
+}
+
+// jsonSafe приводит декодированное значение к виду, который принимает json.Marshal.
+func jsonSafe(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		for key, item := range value {
+			value[key] = jsonSafe(item)
+		}
+		return value
+	case []any:
+		for i, item := range value {
+			value[i] = jsonSafe(item)
+		}
+		return value
+	case float32:
+		return jsonFloat(float64(value))
+	case float64:
+		return jsonFloat(value)
+	case *big.Rat:
+		return decimalString(value)
+	default:
+		return value
+	}
+}
+
+func jsonFloat(f float64) any {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	default:
+		return f
+	}
+}
+
+// decimalString выводит decimal без потери точности: знаменатель значения из Avro — делитель 10^scale.
+func decimalString(r *big.Rat) string {
+	denominator := r.Denom()
+	power := big.NewInt(1)
+	ten := big.NewInt(10)
+	for scale := 0; scale <= maxDecimalScale; scale++ {
+		if new(big.Int).Mod(power, denominator).Sign() == 0 {
+			return r.FloatString(scale)
+		}
+		power.Mul(power, ten)
+	}
+	return r.FloatString(maxDecimalScale)
+}
+
+const maxDecimalScale = 38
@@ -0,0 +1,113 @@
+package kafka
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"CB_auto/internal/config"
+
+	"github.com/segmentio/kafka-go"
+)
+
+const (
+	FormatJSON     = "json"
+	FormatProtobuf = "protobuf"
+	FormatAvro     = "avro"
+)
+
+// Decoder приводит значение сообщения топика к JSON, чтобы типизированные фильтры
+// FindMessage работали одинаково для JSON, protobuf и Avro.
+type Decoder interface {
+	Decode(msg kafka.Message) ([]byte, error)
+}
+
+type jsonDecoder struct{}
+
+func (jsonDecoder) Decode(msg kafka.Message) ([]byte, error) {
+	return msg.Value, nil
+}
+
+// JSONDecoder используется для топиков без явно настроенного формата.
+var JSONDecoder Decoder = jsonDecoder{}
+
+var (
+	decodersMu sync.RWMutex
+	decoders   = make(map[TopicPart]Decoder)
+)
+
+// RegisterDecoder задает декодер для топика вместо настроенного в kafka.decoders.
+func RegisterDecoder(part TopicPart, decoder Decoder) {
+	decodersMu.Lock()
+	defer decodersMu.Unlock()
+	decoders[part] = decoder
+}
+
+// NewDecoder создает декодер по описанию из kafka.decoders.
+func NewDecoder(cfg config.KafkaDecoderConfig) (Decoder, error) {
+	switch cfg.Format {
+	case "", FormatJSON:
+		return JSONDecoder, nil
+	case FormatProtobuf:
+		return newProtobufDecoder(cfg)
+	case FormatAvro:
+		return newAvroDecoder(cfg)
+	default:
+		return nil, fmt.Errorf("kafka: unknown decoder format %q", cfg.Format)
+	}
+}
+
+// decoderFor возвращает декодер топика: зарегистрированный через RegisterDecoder, из конфигурации или JSON.
+func decoderFor(cfg *config.Config, topic TopicType) Decoder {
+	decodersMu.RLock()
+	defer decodersMu.RUnlock()
+
+	for part, decoder := range decoders {
+		if Topic(part) == topic {
+			return decoder
+		}
+	}
+	for part, decoderCfg := range cfg.Kafka.Decoders {
+		if Topic(TopicPart(part)) != topic {
+			continue
+		}
+		decoder, err := NewDecoder(decoderCfg)
+		if err != nil {
+			return failingDecoder{err: err}
+		}
+		return decoder
+	}
+	return JSONDecoder
+}
+
+// failingDecoder возвращает ошибку конфигурации для каждого сообщения, чтобы она попала в SearchError.
+type failingDecoder struct {
+	err error
+}
+
+func (d failingDecoder) Decode(kafka.Message) ([]byte, error) {
+	return nil, d.err
+}
+
+// decodeInto декодирует сообщение в T тем же путем, что и FindMessage.
+func decodeInto[T any](decoder Decoder, msg kafka.Message) (T, error) {
+	var data T
+	value, err := decoder.Decode(msg)
+	if err != nil {
+		return data, err
+	}
+	err = json.Unmarshal(value, &data)
+	return data, err
+}
+
+var errNotConfluentFramed = errors.New("kafka: value is not in schema registry wire format")
+
+// splitConfluentFrame отделяет заголовок schema registry: магический байт 0 и 4 байта идентификатора схемы.
+func splitConfluentFrame(value []byte) (int, []byte, error) {
+	if len(value) < 5 || value[0] != 0 {
+		return 0, nil, errNotConfluentFramed
+	}
+	return int(binary.BigEndian.Uint32(value[1:5])), value[5:], nil
+}
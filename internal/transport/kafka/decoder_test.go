@@ -0,0 +1,346 @@
+package kafka
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"CB_auto/internal/config"
+
+	"github.com/hamba/avro/v2"
+	"github.com/segmentio/kafka-go"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestNewDecoder(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.KafkaDecoderConfig
+		wantErr bool
+	}{
+		{name: "default format", cfg: config.KafkaDecoderConfig{}},
+		{name: "json", cfg: config.KafkaDecoderConfig{Format: FormatJSON}},
+		{name: "unknown format", cfg: config.KafkaDecoderConfig{Format: "xml"}, wantErr: true},
+		{name: "protobuf without descriptor set", cfg: config.KafkaDecoderConfig{Format: FormatProtobuf, MessageType: "test.Event"}, wantErr: true},
+		{name: "protobuf with missing file", cfg: config.KafkaDecoderConfig{Format: FormatProtobuf, DescriptorSet: "missing.pb", MessageType: "test.Event"}, wantErr: true},
+		{name: "avro without schema source", cfg: config.KafkaDecoderConfig{Format: FormatAvro}, wantErr: true},
+		{name: "avro with schema dir", cfg: config.KafkaDecoderConfig{Format: FormatAvro, SchemaDir: t.TempDir()}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewDecoder(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewDecoder() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDecoderFor(t *testing.T) {
+	ConfigureTopics("test.", nil)
+	t.Cleanup(func() {
+		ConfigureTopics("", nil)
+		decodersMu.Lock()
+		decoders = make(map[TopicPart]Decoder)
+		decodersMu.Unlock()
+	})
+
+	registered := NewAvroDecoder(DirSource{Dir: t.TempDir()})
+	RegisterDecoder(GameTopicPart, registered)
+
+	cfg := &config.Config{Kafka: config.KafkaConfig{Decoders: map[string]config.KafkaDecoderConfig{
+		string(LimitTopicPart):   {Format: FormatAvro, SchemaDir: t.TempDir()},
+		string(PaymentTopicPart): {Format: "xml"},
+	}}}
+
+	tests := []struct {
+		name  string
+		topic TopicType
+		check func(Decoder) bool
+	}{
+		{name: "registered decoder", topic: Topic(GameTopicPart), check: func(d Decoder) bool { return d == registered }},
+		{name: "configured decoder", topic: Topic(LimitTopicPart), check: func(d Decoder) bool { _, ok := d.(*AvroDecoder); return ok }},
+		{name: "invalid config", topic: Topic(PaymentTopicPart), check: func(d Decoder) bool { _, ok := d.(failingDecoder); return ok }},
+		{name: "json by default", topic: Topic(PlayerTopicPart), check: func(d Decoder) bool { return d == JSONDecoder }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if decoder := decoderFor(cfg, tt.topic); !tt.check(decoder) {
+				t.Errorf("decoderFor(%s) = %T", tt.topic, decoder)
+			}
+		})
+	}
+}
+
+func testDescriptorSet(t *testing.T) ([]byte, protoreflect.MessageDescriptor) {
+	t.Helper()
+	field := func(name string, number int32, fieldType descriptorpb.FieldDescriptorProto_Type) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name:   proto.String(name),
+			Number: proto.Int32(number),
+			Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:   fieldType.Enum(),
+		}
+	}
+	ids := field("ids", 4, descriptorpb.FieldDescriptorProto_TYPE_INT64)
+	ids.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	limit := field("limit", 5, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE)
+	limit.TypeName = proto.String(".google.protobuf.Int64Value")
+
+	wrappers := protodesc.ToFileDescriptorProto(wrapperspb.File_google_protobuf_wrappers_proto)
+	file := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("test/event.proto"),
+		Package:    proto.String("test"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{wrappers.GetName()},
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Event"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("player_uuid", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				field("amount", 2, descriptorpb.FieldDescriptorProto_TYPE_INT64),
+				field("rate", 3, descriptorpb.FieldDescriptorProto_TYPE_DOUBLE),
+				ids,
+				limit,
+			},
+		}},
+	}
+
+	set, err := proto.Marshal(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{wrappers, file}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fd, err := protodesc.NewFile(file, protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return set, fd.Messages().ByName("Event")
+}
+
+func TestProtobufDecoder(t *testing.T) {
+	set, descriptor := testDescriptorSet(t)
+
+	encode := func(rate float64) []byte {
+		message := dynamicpb.NewMessage(descriptor)
+		message.Set(descriptor.Fields().ByName("player_uuid"), protoreflect.ValueOfString("p-1"))
+		message.Set(descriptor.Fields().ByName("amount"), protoreflect.ValueOfInt64(9007199254740993))
+		message.Set(descriptor.Fields().ByName("rate"), protoreflect.ValueOfFloat64(rate))
+		list := message.Mutable(descriptor.Fields().ByName("ids")).List()
+		list.Append(protoreflect.ValueOfInt64(1))
+		list.Append(protoreflect.ValueOfInt64(-2))
+		message.Set(descriptor.Fields().ByName("limit"), protoreflect.ValueOfMessage(wrapperspb.Int64(500).ProtoReflect()))
+		data, err := proto.Marshal(message)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+	framed := append([]byte{0, 0, 0, 0, 7, 0}, encode(1.5)...)
+	ids := []any{json.Number("1"), json.Number("-2")}
+
+	tests := []struct {
+		name          string
+		value         []byte
+		framing       bool
+		useProtoNames bool
+		want          map[string]any
+		wantErr       bool
+	}{
+		{
+			name:  "int64 as number",
+			value: encode(1.5),
+			want:  map[string]any{"playerUuid": "p-1", "amount": json.Number("9007199254740993"), "rate": json.Number("1.5"), "ids": ids, "limit": json.Number("500")},
+		},
+		{
+			name:  "NaN",
+			value: encode(math.NaN()),
+			want:  map[string]any{"playerUuid": "p-1", "amount": json.Number("9007199254740993"), "rate": "NaN", "ids": ids, "limit": json.Number("500")},
+		},
+		{
+			name:  "infinity",
+			value: encode(math.Inf(-1)),
+			want:  map[string]any{"playerUuid": "p-1", "amount": json.Number("9007199254740993"), "rate": "-Infinity", "ids": ids, "limit": json.Number("500")},
+		},
+		{
+			name:          "proto names",
+			value:         encode(1.5),
+			useProtoNames: true,
+			want:          map[string]any{"player_uuid": "p-1", "amount": json.Number("9007199254740993"), "rate": json.Number("1.5"), "ids": ids, "limit": json.Number("500")},
+		},
+		{
+			name:    "confluent framing",
+			value:   framed,
+			framing: true,
+			want:    map[string]any{"playerUuid": "p-1", "amount": json.Number("9007199254740993"), "rate": json.Number("1.5"), "ids": ids, "limit": json.Number("500")},
+		},
+		{
+			name:    "unframed value with framing enabled",
+			value:   encode(1.5),
+			framing: true,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoder, err := NewProtobufDecoder(set, ".test.Event", tt.framing, tt.useProtoNames)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := decoder.Decode(kafka.Message{Value: tt.value})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Decode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			assertJSON(t, got, tt.want)
+		})
+	}
+}
+
+func TestProtobufDecoderTypedMessage(t *testing.T) {
+	set, descriptor := testDescriptorSet(t)
+
+	message := dynamicpb.NewMessage(descriptor)
+	message.Set(descriptor.Fields().ByName("amount"), protoreflect.ValueOfInt64(9007199254740993))
+	message.Set(descriptor.Fields().ByName("limit"), protoreflect.ValueOfMessage(wrapperspb.Int64(-7).ProtoReflect()))
+	value, err := proto.Marshal(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoder, err := NewProtobufDecoder(set, "test.Event", false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := decoder.Decode(kafka.Message{Value: value})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var event struct {
+		Amount int64 `json:"amount"`
+		Limit  int   `json:"limit"`
+	}
+	if err := json.Unmarshal(data, &event); err != nil {
+		t.Fatalf("json.Unmarshal(%s) error = %v", data, err)
+	}
+	if event.Amount != 9007199254740993 || event.Limit != -7 {
+		t.Errorf("decoded = %+v, want amount 9007199254740993 and limit -7", event)
+	}
+}
+
+func TestNewProtobufDecoderUnknownType(t *testing.T) {
+	set, _ := testDescriptorSet(t)
+	if _, err := NewProtobufDecoder(set, "test.Missing", false, false); err == nil {
+		t.Error("NewProtobufDecoder() error = nil for unknown message type")
+	}
+}
+
+const testAvroSchema = `{
+	"type": "record",
+	"name": "Event",
+	"fields": [
+		{"name": "player_uuid", "type": "string"},
+		{"name": "bonus", "type": ["null", "string"]},
+		{"name": "rate", "type": "double"},
+		{"name": "amount", "type": {"type": "bytes", "logicalType": "decimal", "precision": 10, "scale": 2}},
+		{"name": "created_at", "type": {"type": "long", "logicalType": "timestamp-millis"}}
+	]
+}`
+
+type testAvroEvent struct {
+	PlayerUUID string   `avro:"player_uuid"`
+	Bonus      *string  `avro:"bonus"`
+	Rate       float64  `avro:"rate"`
+	Amount     *big.Rat `avro:"amount"`
+	CreatedAt  int64    `avro:"created_at"`
+}
+
+func TestAvroDecoder(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "42.avsc"), []byte(testAvroSchema), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	schema, err := withoutTimeLogicalTypes(testAvroSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writerSchema := avro.MustParse(schema)
+
+	encode := func(id uint32, event testAvroEvent) []byte {
+		data, err := avro.Marshal(writerSchema, event)
+		if err != nil {
+			t.Fatal(err)
+		}
+		header := make([]byte, 5)
+		binary.BigEndian.PutUint32(header[1:], id)
+		return append(header, data...)
+	}
+	bonus := "b-1"
+
+	tests := []struct {
+		name    string
+		value   []byte
+		want    map[string]any
+		wantErr bool
+	}{
+		{
+			name:  "union, decimal and timestamp",
+			value: encode(42, testAvroEvent{PlayerUUID: "p-1", Bonus: &bonus, Rate: 0.5, Amount: big.NewRat(1050, 100), CreatedAt: 1700000000000}),
+			want:  map[string]any{"player_uuid": "p-1", "bonus": "b-1", "rate": json.Number("0.5"), "amount": "10.5", "created_at": json.Number("1700000000000")},
+		},
+		{
+			name:  "null union and NaN",
+			value: encode(42, testAvroEvent{PlayerUUID: "p-1", Rate: math.NaN(), Amount: big.NewRat(0, 1)}),
+			want:  map[string]any{"player_uuid": "p-1", "bonus": nil, "rate": "NaN", "amount": "0", "created_at": json.Number("0")},
+		},
+		{
+			name:    "unknown schema id",
+			value:   encode(7, testAvroEvent{PlayerUUID: "p-1", Amount: big.NewRat(0, 1)}),
+			wantErr: true,
+		},
+		{
+			name:    "not framed",
+			value:   []byte(`{"player_uuid":"p-1"}`),
+			wantErr: true,
+		},
+	}
+	decoder := NewAvroDecoder(DirSource{Dir: dir})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decoder.Decode(kafka.Message{Value: tt.value})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Decode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			assertJSON(t, got, tt.want)
+		})
+	}
+}
+
+func assertJSON(t *testing.T, data []byte, want map[string]any) {
+	t.Helper()
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var got map[string]any
+	if err := decoder.Decode(&got); err != nil {
+		t.Fatalf("invalid JSON %s: %v", data, err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("decoded = %v, want %v", got, want)
+	}
+}
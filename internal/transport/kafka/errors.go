@@ -0,0 +1,56 @@
+package kafka
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrTimeout       = errors.New("kafka: timeout waiting for message")
+	ErrChannelClosed = errors.New("kafka: subscription channel closed")
+	// ErrUnsupportedFormat возвращает Produce для топиков с форматом protobuf или avro.
+	ErrUnsupportedFormat = errors.New("kafka: producing supports only JSON topics")
+)
+
+// UnmarshalError описывает сообщение топика, которое не удалось разобрать в ожидаемый тип.
+type UnmarshalError struct {
+	Topic     TopicType
+	Partition int
+	Offset    int64
+	Err       error
+}
+
+func (e *UnmarshalError) Error() string {
+	return fmt.Sprintf("kafka: unmarshal message %s[%d]@%d: %v", e.Topic, e.Partition, e.Offset, e.Err)
+}
+
+func (e *UnmarshalError) Unwrap() error {
+	return e.Err
+}
+
+// SearchError возвращается FindMessage, если подходящее сообщение не найдено.
+// Err равен ErrTimeout или ErrChannelClosed, Unmarshal содержит ошибки разбора, встреченные во время поиска.
+type SearchError struct {
+	Topic     TopicType
+	Timeout   time.Duration
+	Scanned   int
+	Unmarshal []*UnmarshalError
+	Err       error
+}
+
+func (e *SearchError) Error() string {
+	msg := fmt.Sprintf("%v: topic %s, timeout %v, scanned %d", e.Err, e.Topic, e.Timeout, e.Scanned)
+	if len(e.Unmarshal) > 0 {
+		msg += fmt.Sprintf(", unmarshal errors %d (last: %v)", len(e.Unmarshal), e.Unmarshal[len(e.Unmarshal)-1].Err)
+	}
+	return msg
+}
+
+func (e *SearchError) Unwrap() []error {
+	errs := []error{e.Err}
+	for _, err := range e.Unmarshal {
+		errs = append(errs, err)
+	}
+	return errs
+}
@@ -0,0 +1,122 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"CB_auto/internal/config"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+)
+
+const (
+	// GroupModePartition читает каждую партицию без группы, начиная со времени создания consumer. Режим по умолчанию.
+	GroupModePartition = "partition"
+	// GroupModeRun подключает readers к группе <node.group_id>-<run_id>, уникальной для запуска.
+	// Группа удаляется с брокера при закрытии consumer.
+	GroupModeRun = "run"
+	// GroupModeShared подключает readers к общей группе node.group_id, как раньше.
+	GroupModeShared = "shared"
+
+	// RunIDEnv позволяет задать идентификатор запуска, например номер CI-джобы.
+	RunIDEnv = "CB_RUN_ID"
+)
+
+// RunID идентифицирует текущий запуск тестов и входит в имя consumer group в режиме GroupModeRun.
+var RunID = func() string {
+	if id := os.Getenv(RunIDEnv); id != "" {
+		return id
+	}
+	return uuid.NewString()
+}()
+
+func consumerGroupID(cfg *config.Config) string {
+	switch cfg.Kafka.GroupMode {
+	case GroupModeShared:
+		return cfg.Node.GroupID
+	case GroupModeRun:
+		return fmt.Sprintf("%s-%s", cfg.Node.GroupID, RunID)
+	default:
+		return ""
+	}
+}
+
+func newReaders(cfg *config.Config, topic TopicType, startedAt time.Time) ([]*kafka.Reader, error) {
+	groupID := consumerGroupID(cfg)
+	if groupID == "" {
+		return newPartitionReaders(cfg.Kafka.Brokers, string(topic), startedAt)
+	}
+
+	log.Printf("Создание Kafka reader: brokers=%v, topic=%s, groupID=%s", cfg.Kafka.Brokers, topic, groupID)
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:        []string{cfg.Kafka.Brokers},
+		Topic:          string(topic),
+		GroupID:        groupID,
+		StartOffset:    kafka.LastOffset,
+		ReadBackoffMin: 100 * time.Millisecond,
+		ReadBackoffMax: 500 * time.Millisecond,
+	})
+	return []*kafka.Reader{reader}, nil
+}
+
+func newPartitionReaders(brokers, topic string, startedAt time.Time) ([]*kafka.Reader, error) {
+	conn, err := kafka.Dial("tcp", brokers)
+	if err != nil {
+		return nil, fmt.Errorf("kafka: connect to %s to read partitions of %s: %w", brokers, topic, err)
+	}
+	defer conn.Close()
+
+	partitions, err := conn.ReadPartitions(topic)
+	if err != nil {
+		return nil, fmt.Errorf("kafka: read partitions of %s: %w", topic, err)
+	}
+	if len(partitions) == 0 {
+		return nil, fmt.Errorf("kafka: topic %s has no partitions", topic)
+	}
+
+	var readers []*kafka.Reader
+	for _, partition := range partitions {
+		log.Printf("Создание Kafka reader без группы: brokers=%v, topic=%s, partition=%d, from=%s",
+			brokers, topic, partition.ID, startedAt.Format(time.RFC3339))
+		reader := kafka.NewReader(kafka.ReaderConfig{
+			Brokers:        []string{brokers},
+			Topic:          topic,
+			Partition:      partition.ID,
+			ReadBackoffMin: 100 * time.Millisecond,
+			ReadBackoffMax: 500 * time.Millisecond,
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if err := reader.SetOffsetAt(ctx, startedAt); err != nil {
+			log.Printf("Ошибка установки смещения по времени для %s[%d]: %v", topic, partition.ID, err)
+		}
+		cancel()
+		readers = append(readers, reader)
+	}
+	return readers, nil
+}
+
+// deleteRunGroup удаляет consumer group запуска, чтобы на брокере не оставались группы прошлых прогонов.
+func deleteRunGroup(cfg *config.Config) error {
+	if cfg.Kafka.GroupMode != GroupModeRun {
+		return nil
+	}
+	groupID := consumerGroupID(cfg)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client := &kafka.Client{Addr: kafka.TCP(cfg.Kafka.Brokers)}
+	res, err := client.DeleteGroups(ctx, &kafka.DeleteGroupsRequest{GroupIDs: []string{groupID}})
+	if err != nil {
+		return fmt.Errorf("kafka: delete consumer group %s: %w", groupID, err)
+	}
+	if err := res.Errors[groupID]; err != nil {
+		return fmt.Errorf("kafka: delete consumer group %s: %w", groupID, err)
+	}
+	return nil
+}
@@ -3,51 +3,21 @@ package kafka
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"CB_auto/internal/config"
+	"CB_auto/pkg/redact"
+	"CB_auto/pkg/utils"
 
+	"github.com/ozontech/allure-go/pkg/allure"
 	"github.com/ozontech/allure-go/pkg/framework/provider"
 	"github.com/segmentio/kafka-go"
 )
 
-type TopicType string
-type TopicPart string
-
-const (
-	BrandTopicPart      TopicPart = "core.gambling.v1.Brand"
-	PlayerTopicPart     TopicPart = "player.v1.account"
-	LimitTopicPart      TopicPart = "limits.v2"
-	ProjectionTopicPart TopicPart = "wallet.v8.projectionSource"
-	GameTopicPart       TopicPart = "core.gambling.v2.Game"
-	PaymentTopicPart    TopicPart = "payment.v1.transaction"
-)
-
-type Topics struct {
-	Brand      TopicType
-	Player     TopicType
-	Limit      TopicType
-	Projection TopicType
-	Game       TopicType
-	Payment    TopicType
-}
-
-func NewTopics(prefix string) Topics {
-	return Topics{
-		Brand:      TopicType(prefix + string(BrandTopicPart)),
-		Player:     TopicType(prefix + string(PlayerTopicPart)),
-		Limit:      TopicType(prefix + string(LimitTopicPart)),
-		Projection: TopicType(prefix + string(ProjectionTopicPart)),
-		Game:       TopicType(prefix + string(GameTopicPart)),
-		Payment:    TopicType(prefix + string(PaymentTopicPart)),
-	}
-}
-
-var TopicsConfig Topics
-
 type subscriber struct {
 	ch     chan kafka.Message
 	done   chan struct{}
@@ -70,54 +40,20 @@ func (sub *subscriber) Close() {
 	})
 }
 
-type RingBuffer struct {
-	mu     sync.Mutex
-	buffer []kafka.Message
-	size   int
-	head   int
-	count  int
-}
-
-func NewRingBuffer(size int) *RingBuffer {
-	return &RingBuffer{
-		buffer: make([]kafka.Message, size),
-		size:   size,
-	}
-}
-
-func (rb *RingBuffer) Add(msg kafka.Message) {
-	rb.mu.Lock()
-	defer rb.mu.Unlock()
-
-	rb.buffer[rb.head] = msg
-	rb.head = (rb.head + 1) % rb.size
-	if rb.count < rb.size {
-		rb.count++
-	}
-}
-
-func (rb *RingBuffer) GetAll() []kafka.Message {
-	rb.mu.Lock()
-	defer rb.mu.Unlock()
-
-	messages := make([]kafka.Message, rb.count)
-	for i := 0; i < rb.count; i++ {
-		index := (rb.head - rb.count + i + rb.size) % rb.size
-		messages[i] = rb.buffer[index]
-	}
-	return messages
-}
-
 type Kafka struct {
-	readers          []*kafka.Reader
-	subscribers      map[TopicType][]*subscriber
-	bufferedMessages map[TopicType]*RingBuffer
-	Timeout          time.Duration
-	ctx              context.Context
-	cancel           context.CancelFunc
-	wg               sync.WaitGroup
-	started          bool
-	mu               sync.RWMutex
+	cfg         *config.Config
+	bufferSize  int
+	retention   time.Duration
+	indexFields []string
+	readers     map[TopicType][]*kafka.Reader
+	subscribers map[TopicType][]*subscriber
+	stores      map[TopicType]*MessageStore
+	Timeout     time.Duration
+	StartedAt   time.Time
+	ctx         context.Context
+	cancel      context.CancelFunc
+	wg          sync.WaitGroup
+	mu          sync.RWMutex
 }
 
 var (
@@ -131,14 +67,18 @@ func GetInstance(t provider.T, cfg *config.Config) *Kafka {
 	instanceMu.Lock()
 	defer instanceMu.Unlock()
 
+	var err error
 	once.Do(func() {
 		t.Logf("Создание синглтона Kafka consumer с динамическими топиками")
-		TopicsConfig = NewTopics(cfg.Kafka.TopicPrefix)
+		ConfigureTopics(cfg.Kafka.TopicPrefix, cfg.Kafka.Topics)
 		instance = newConsumer(cfg)
-		instance.startReading()
+		err = instance.watch(eagerTopics(cfg)...)
 	})
 	refCount++
 	t.Logf("Kafka instance refCount increased to %d", refCount)
+	if err != nil {
+		t.Fatalf("Ошибка запуска Kafka readers: %v", err)
+	}
 	return instance
 }
 
@@ -158,67 +98,114 @@ func CloseInstance(t provider.T) {
 	}
 }
 
-func newConsumer(cfg *config.Config) *Kafka {
-	var readers []*kafka.Reader
-	subscribers := make(map[TopicType][]*subscriber)
-	bufferedMessages := make(map[TopicType]*RingBuffer)
+// DefaultEagerTopics — топики, которые читались consumer до появления реестра.
+var DefaultEagerTopics = []TopicPart{
+	BrandTopicPart,
+	PlayerTopicPart,
+	LimitTopicPart,
+	ProjectionTopicPart,
+	GameTopicPart,
+	PaymentTopicPart,
+}
+
+// eagerTopics возвращает топики из kafka.eager_topics, readers которых запускаются сразу при создании consumer,
+// чтобы существующие сценарии не пропускали события, опубликованные до первого поиска.
+func eagerTopics(cfg *config.Config) []TopicType {
+	parts := DefaultEagerTopics
+	if len(cfg.Kafka.EagerTopics) > 0 {
+		parts = make([]TopicPart, len(cfg.Kafka.EagerTopics))
+		for i, part := range cfg.Kafka.EagerTopics {
+			parts[i] = TopicPart(part)
+		}
+	}
 
-	topicList := []TopicType{
-		TopicsConfig.Brand,
-		TopicsConfig.Player,
-		TopicsConfig.Limit,
-		TopicsConfig.Projection,
-		TopicsConfig.Game,
-		TopicsConfig.Payment,
+	result := make([]TopicType, len(parts))
+	for i, part := range parts {
+		result[i] = Topic(part)
 	}
+	return result
+}
 
+func newConsumer(cfg *config.Config) *Kafka {
 	bufferSize := cfg.Kafka.BufferSize
 	if bufferSize < 1 {
 		bufferSize = 500
 	}
 
-	for _, topic := range topicList {
-		bufferedMessages[topic] = NewRingBuffer(bufferSize)
+	indexFields := cfg.Kafka.IndexFields
+	if len(indexFields) == 0 {
+		indexFields = DefaultIndexFields
 	}
 
-	for _, topicType := range topicList {
-		topic := string(topicType)
-		log.Printf("Создание Kafka reader: brokers=%v, topic=%s, groupID=%s", cfg.Kafka.Brokers, topic, cfg.Node.GroupID)
-		reader := kafka.NewReader(kafka.ReaderConfig{
-			Brokers:        []string{cfg.Kafka.Brokers},
-			Topic:          topic,
-			GroupID:        cfg.Node.GroupID,
-			StartOffset:    kafka.LastOffset,
-			ReadBackoffMin: 100 * time.Millisecond,
-			ReadBackoffMax: 500 * time.Millisecond,
-		})
-		readers = append(readers, reader)
+	timeout := cfg.Kafka.GetTimeout()
+	if timeout <= 0 {
+		timeout = 120 * time.Second
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 	return &Kafka{
-		readers:          readers,
-		subscribers:      subscribers,
-		bufferedMessages: bufferedMessages,
-		Timeout:          120 * time.Second,
-		ctx:              ctx,
-		cancel:           cancel,
+		cfg:         cfg,
+		bufferSize:  bufferSize,
+		retention:   cfg.Kafka.Retention,
+		indexFields: indexFields,
+		readers:     make(map[TopicType][]*kafka.Reader),
+		subscribers: make(map[TopicType][]*subscriber),
+		stores:      make(map[TopicType]*MessageStore),
+		Timeout:     timeout,
+		StartedAt:   time.Now(),
+		ctx:         ctx,
+		cancel:      cancel,
 	}
 }
 
-func (k *Kafka) startReading() {
+// Watch запускает чтение топика сообщений T заранее, например в BeforeAll,
+// чтобы первый поиск не пропустил события, опубликованные до него.
+func Watch[T KafkaMessage](t provider.T, k *Kafka) {
+	if err := k.watch(GetTopicForType[T]()); err != nil {
+		t.Fatalf("Ошибка запуска Kafka readers: %v", err)
+	}
+}
+
+func (k *Kafka) watch(topics ...TopicType) error {
 	k.mu.Lock()
-	if k.started {
-		k.mu.Unlock()
-		return
+	defer k.mu.Unlock()
+
+	var errs []error
+	for _, topic := range topics {
+		if _, err := k.ensureTopic(topic); err != nil {
+			errs = append(errs, err)
+		}
 	}
-	k.started = true
-	k.mu.Unlock()
+	return errors.Join(errs...)
+}
+
+// ensureTopic лениво создает хранилище и readers топика. Вызывается под k.mu.
+// Если readers создать не удалось, топик не регистрируется и следующий вызов повторит попытку.
+func (k *Kafka) ensureTopic(topic TopicType) (*MessageStore, error) {
+	if store, ok := k.stores[topic]; ok {
+		return store, nil
+	}
+
+	readers, err := newReaders(k.cfg, topic, k.StartedAt)
+	if err != nil {
+		return nil, err
+	}
+	store := NewMessageStore(decoderFor(k.cfg, topic), k.indexFields, k.retention, k.bufferSize)
+	k.stores[topic] = store
+	k.readers[topic] = readers
 
-	k.wg.Add(len(k.readers))
-	for _, reader := range k.readers {
+	k.wg.Add(len(readers))
+	for _, reader := range readers {
 		go k.readMessages(reader)
 	}
+	return store, nil
+}
+
+// Store возвращает хранилище сообщений топика, запуская его чтение при необходимости.
+func (k *Kafka) Store(topic TopicType) (*MessageStore, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.ensureTopic(topic)
 }
 
 func (k *Kafka) readMessages(reader *kafka.Reader) {
@@ -241,14 +228,20 @@ func (k *Kafka) readMessages(reader *kafka.Reader) {
 				continue
 			}
 			log.Printf("Получено сообщение из топика %s, partition %d, offset %d: %s",
-				msg.Topic, msg.Partition, msg.Offset, string(msg.Value))
+				msg.Topic, msg.Partition, msg.Offset, redact.JSON(msg.Value))
 
 			topic := TopicType(msg.Topic)
-			k.mu.Lock()
-			k.bufferedMessages[topic].Add(msg)
+			k.mu.RLock()
+			store := k.stores[topic]
+			k.mu.RUnlock()
+
+			// Декодирование и индексация идут вне k.mu, чтобы readers разных топиков не ждали друг друга.
+			store.Add(msg)
+
+			k.mu.RLock()
 			subs := make([]*subscriber, len(k.subscribers[topic]))
 			copy(subs, k.subscribers[topic])
-			k.mu.Unlock()
+			k.mu.RUnlock()
 
 			for _, sub := range subs {
 				if sub.closed.Load() {
@@ -265,50 +258,264 @@ func (k *Kafka) readMessages(reader *kafka.Reader) {
 	}
 }
 
-func FindMessageByFilter[T KafkaMessage](sCtx provider.StepCtx, k *Kafka, filter func(T) bool) T {
+// FindMessageByFilter ждет сообщение в течение Kafka.Timeout и возвращает нулевое значение, если оно не найдено.
+// Опции ByKey, ByField и ByHeader, повторяющие условие filter, сужают просмотр хранилища до индекса.
+func FindMessageByFilter[T KafkaMessage](sCtx provider.StepCtx, k *Kafka, filter func(T) bool, opts ...FindOption) T {
+	data, err := FindMessage(sCtx, k, filter, opts...)
+	if err != nil {
+		sCtx.Logf("Сообщение не найдено: %v", err)
+	}
+	return data
+}
+
+const defaultDiagnosticsSize = 10
+
+type findOptions struct {
+	timeout     time.Duration
+	diagnostics int
+	since       time.Time
+	latest      bool
+	lookup      Lookup
+}
+
+func (o findOptions) skip(msg kafka.Message) bool {
+	return !o.since.IsZero() && msg.Time.Before(o.since)
+}
+
+// FindOption настраивает отдельный вызов FindMessage.
+type FindOption func(*findOptions)
+
+// WithTimeout задает таймаут поиска вместо Kafka.Timeout.
+func WithTimeout(timeout time.Duration) FindOption {
+	return func(o *findOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithDiagnostics задает число последних неподходящих сообщений, прикладываемых к шагу при неудаче.
+func WithDiagnostics(n int) FindOption {
+	return func(o *findOptions) {
+		o.diagnostics = n
+	}
+}
+
+type diagnosticMessage struct {
+	Partition int             `json:"partition"`
+	Offset    int64           `json:"offset"`
+	Key       string          `json:"key,omitempty"`
+	Time      time.Time       `json:"time"`
+	Value     json.RawMessage `json:"value,omitempty"`
+	RawValue  string          `json:"raw_value,omitempty"`
+}
+
+func newDiagnosticMessage(msg kafka.Message) diagnosticMessage {
+	d := diagnosticMessage{
+		Partition: msg.Partition,
+		Offset:    msg.Offset,
+		Key:       string(msg.Key),
+		Time:      msg.Time,
+	}
+	if json.Valid(msg.Value) {
+		d.Value = msg.Value
+	} else {
+		d.RawValue = string(msg.Value)
+	}
+	return d
+}
+
+// Since пропускает сообщения с временем публикации раньше t, например времени старта теста.
+// Хранилище топика при этом просматривается, поэтому сообщение, пришедшее до начала поиска, будет найдено.
+func Since(t time.Time) FindOption {
+	return func(o *findOptions) {
+		o.since = t
+	}
+}
+
+// Latest учитывает только сообщения, полученные после начала поиска, без просмотра хранилища топика.
+func Latest() FindOption {
+	return func(o *findOptions) {
+		o.latest = true
+	}
+}
+
+// ByKey ищет только среди сообщений с указанным ключом.
+func ByKey(key string) FindOption {
+	return func(o *findOptions) {
+		o.lookup = Lookup{Key: key}
+	}
+}
+
+// ByField ищет только среди сообщений, у которых JSON-поле path равно value.
+// Если поле не входит в kafka.index_fields (или DefaultIndexFields), просматриваются все сообщения топика.
+func ByField(path, value string) FindOption {
+	return func(o *findOptions) {
+		o.lookup = Lookup{Field: path, FieldValue: value}
+	}
+}
+
+// ByHeader ищет только среди сообщений с заголовком name = value.
+func ByHeader(name, value string) FindOption {
+	return func(o *findOptions) {
+		o.lookup = Lookup{Header: name, HeaderValue: value}
+	}
+}
+
+// FindMessage ищет сообщение, прошедшее filter, и возвращает *SearchError при таймауте или закрытии подписки.
+// При неудаче к шагу прикладываются последние неподходящие сообщения топика.
+func FindMessage[T KafkaMessage](sCtx provider.StepCtx, k *Kafka, filter func(T) bool, opts ...FindOption) (T, error) {
+	options := findOptions{
+		timeout:     k.Timeout,
+		diagnostics: defaultDiagnosticsSize,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	var empty T
-	var tmp T
-	topic := tmp.GetTopic()
+	topic := GetTopicForType[T]()
+	store, err := k.Store(topic)
+	if err != nil {
+		sCtx.Errorf("Ошибка запуска Kafka readers для топика %s: %v", topic, err)
+		return empty, err
+	}
 
-	ch := k.SubscribeToTopic(topic)
-	defer k.Unsubscribe(ch)
+	var cursor uint64
+	if options.latest {
+		cursor = store.LastID()
+	}
 
-	ctx, cancel := context.WithTimeout(k.ctx, k.Timeout)
+	ctx, cancel := context.WithTimeout(k.ctx, options.timeout)
 	defer cancel()
 
-	sCtx.Logf("Начало поиска сообщения в топике %s с таймаутом %v", topic, k.Timeout)
+	searchErr := &SearchError{Topic: topic, Timeout: options.timeout}
+	var rejected []diagnosticMessage
+	fail := func(err error) (T, error) {
+		searchErr.Err = err
+		if len(rejected) > 0 {
+			sCtx.WithAttachments(allure.NewAttachment("Kafka Rejected Messages", allure.JSON, utils.CreatePrettyJSON(rejected)))
+		}
+		return empty, searchErr
+	}
+
+	sCtx.Logf("Начало поиска сообщения в топике %s с таймаутом %v", topic, options.timeout)
 	for {
+		messages, updated := store.Since(cursor, options.lookup)
+		for _, stored := range messages {
+			cursor = stored.id
+			msg := stored.msg
+			if options.skip(msg) {
+				continue
+			}
+			searchErr.Scanned++
+			sCtx.Logf("Получено сообщение: %s", redact.JSON(msg.Value))
+
+			data, err := decodeInto[T](store.Decoder(), msg)
+			if err != nil {
+				sCtx.Logf("Ошибка парсинга сообщения в тип %T: %v", data, err)
+				searchErr.Unmarshal = append(searchErr.Unmarshal, &UnmarshalError{
+					Topic:     topic,
+					Partition: msg.Partition,
+					Offset:    msg.Offset,
+					Err:       err,
+				})
+			} else if filter(data) {
+				sCtx.Logf("Найдено подходящее сообщение в топике %s", topic)
+				sCtx.WithAttachments(allure.NewAttachment("Kafka Message", allure.JSON, utils.CreatePrettyJSON(data)))
+				return data, nil
+			}
+
+			if options.diagnostics > 0 {
+				rejected = append(rejected, newDiagnosticMessage(msg))
+				if len(rejected) > options.diagnostics {
+					rejected = rejected[1:]
+				}
+			}
+		}
+
 		select {
 		case <-ctx.Done():
+			if k.ctx.Err() != nil {
+				sCtx.Logf("Kafka consumer для топика %s закрыт", topic)
+				return fail(ErrChannelClosed)
+			}
 			sCtx.Logf("Таймаут ожидания сообщения в топике %s", topic)
-			return empty
-		case msg, ok := <-ch:
-			if !ok {
-				sCtx.Logf("Канал подписки для топика %s закрыт", topic)
-				return empty
+			return fail(ErrTimeout)
+		case <-updated:
+		}
+	}
+}
+
+// AssertNoMessage проверяет, что в топике сообщения T за период тишины quiet не появилось сообщений, прошедших filter.
+// Возвращает false и отмечает ошибку в шаге, если подходящее сообщение получено или подписка прервана.
+// Проверяются только сообщения, полученные после вызова; Since добавляет к ним уже сохраненные,
+// опубликованные не раньше t.
+func AssertNoMessage[T KafkaMessage](sCtx provider.StepCtx, k *Kafka, quiet time.Duration, filter func(T) bool, opts ...FindOption) bool {
+	var options findOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	topic := GetTopicForType[T]()
+	store, err := k.Store(topic)
+	if err != nil {
+		sCtx.Errorf("Ошибка запуска Kafka readers для топика %s: %v", topic, err)
+		return false
+	}
+
+	var cursor uint64
+	if options.since.IsZero() {
+		cursor = store.LastID()
+	}
+
+	ctx, cancel := context.WithTimeout(k.ctx, quiet)
+	defer cancel()
+
+	sCtx.Logf("Ожидание отсутствия сообщений в топике %s в течение %v", topic, quiet)
+	for {
+		messages, updated := store.Since(cursor, options.lookup)
+		for _, stored := range messages {
+			cursor = stored.id
+			if options.skip(stored.msg) {
+				continue
 			}
-			sCtx.Logf("Получено сообщение: %s", string(msg.Value))
-			var data T
-			if err := json.Unmarshal(msg.Value, &data); err != nil {
-				sCtx.Logf("Ошибка парсинга сообщения в тип %T: %v", data, err)
+			data, err := decodeInto[T](store.Decoder(), stored.msg)
+			if err != nil {
 				continue
 			}
 			if filter(data) {
-				sCtx.Logf("Найдено подходящее сообщение в топике %s", topic)
-				return data
+				sCtx.WithAttachments(allure.NewAttachment("Unexpected Kafka Message", allure.JSON, utils.CreatePrettyJSON(data)))
+				sCtx.Errorf("Получено неожиданное сообщение в топике %s", topic)
+				return false
 			}
 		}
+
+		select {
+		case <-ctx.Done():
+			if k.ctx.Err() != nil {
+				sCtx.Errorf("Kafka consumer закрыт до окончания периода тишины в топике %s", topic)
+				return false
+			}
+			sCtx.Logf("Подходящих сообщений в топике %s не получено", topic)
+			return true
+		case <-updated:
+		}
 	}
 }
 
-func (k *Kafka) SubscribeToTopic(topic TopicType) chan kafka.Message {
+// SubscribeToTopic подписывается на топик: подписчик сначала получает сообщения из хранилища, затем новые.
+func (k *Kafka) SubscribeToTopic(topic TopicType) (chan kafka.Message, error) {
 	k.mu.Lock()
 	defer k.mu.Unlock()
 
-	buffered := k.bufferedMessages[topic].GetAll()
+	store, err := k.ensureTopic(topic)
+	if err != nil {
+		return nil, err
+	}
 	sub := newSubscriber()
 	k.subscribers[topic] = append(k.subscribers[topic], sub)
 
+	buffered := store.All()
+
 	go func() {
 		for _, msg := range buffered {
 			select {
@@ -319,7 +526,7 @@ func (k *Kafka) SubscribeToTopic(topic TopicType) chan kafka.Message {
 		}
 	}()
 
-	return sub.ch
+	return sub.ch, nil
 }
 
 func (k *Kafka) Unsubscribe(ch chan kafka.Message) {
@@ -349,9 +556,16 @@ func (k *Kafka) close(t provider.T) {
 	k.subscribers = nil
 	k.mu.Unlock()
 
+	k.mu.RLock()
+	var readers []*kafka.Reader
+	for _, topicReaders := range k.readers {
+		readers = append(readers, topicReaders...)
+	}
+	k.mu.RUnlock()
+
 	var closeWg sync.WaitGroup
-	closeWg.Add(len(k.readers))
-	for _, reader := range k.readers {
+	closeWg.Add(len(readers))
+	for _, reader := range readers {
 		go func(r *kafka.Reader) {
 			defer closeWg.Done()
 			_ = r.Close()
@@ -367,33 +581,8 @@ func (k *Kafka) close(t provider.T) {
 	case <-time.After(5 * time.Second):
 		t.Logf("Таймаут при закрытии Kafka reader. Форсированное закрытие")
 	}
-}
-
-type KafkaMessage interface {
-	GetTopic() TopicType
-}
-
-func (m PlayerMessage) GetTopic() TopicType {
-	return TopicsConfig.Player
-}
 
-func (m LimitMessage) GetTopic() TopicType {
-	return TopicsConfig.Limit
-}
-
-func (m ProjectionSourceMessage) GetTopic() TopicType {
-	return TopicsConfig.Projection
-}
-
-func (m GameMessage) GetTopic() TopicType {
-	return TopicsConfig.Game
-}
-
-func (m TransactionMessage) GetTopic() TopicType {
-	return TopicsConfig.Payment
-}
-
-func GetTopicForType[T KafkaMessage]() TopicType {
-	var msg T
-	return msg.GetTopic()
+	if err := deleteRunGroup(k.cfg); err != nil {
+		t.Logf("Ошибка удаления consumer group запуска: %v", err)
+	}
 }
@@ -0,0 +1,162 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"CB_auto/internal/config"
+	"CB_auto/pkg/utils"
+
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/segmentio/kafka-go"
+)
+
+// Producer публикует типизированные сообщения в топики реестра, имитируя upstream-сервисы.
+type Producer struct {
+	brokers string
+	writer  *kafka.Writer
+	timeout time.Duration
+	cfg     *config.Config
+}
+
+func NewProducer(cfg *config.Config) *Producer {
+	ConfigureTopics(cfg.Kafka.TopicPrefix, cfg.Kafka.Topics)
+
+	timeout := cfg.Kafka.GetTimeout()
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	return &Producer{
+		brokers: cfg.Kafka.Brokers,
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(cfg.Kafka.Brokers),
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireAll,
+			// Produce пишет по одному сообщению синхронно, поэтому пачку не ждем (по умолчанию 1s).
+			BatchTimeout: 5 * time.Millisecond,
+		},
+		timeout: timeout,
+		cfg:     cfg,
+	}
+}
+
+type produceOptions struct {
+	key       []byte
+	headers   []kafka.Header
+	partition *int
+}
+
+// ProduceOption настраивает отдельное сообщение Produce.
+type ProduceOption func(*produceOptions)
+
+// WithKey задает ключ сообщения; без WithPartition партиция выбирается по хешу ключа.
+func WithKey(key string) ProduceOption {
+	return func(o *produceOptions) {
+		o.key = []byte(key)
+	}
+}
+
+func WithHeader(key, value string) ProduceOption {
+	return func(o *produceOptions) {
+		o.headers = append(o.headers, kafka.Header{Key: key, Value: []byte(value)})
+	}
+}
+
+// WithPartition публикует сообщение в указанную партицию через лидера партиции.
+func WithPartition(partition int) ProduceOption {
+	return func(o *produceOptions) {
+		o.partition = &partition
+	}
+}
+
+type producedMessage struct {
+	Topic     TopicType         `json:"topic"`
+	Partition *int              `json:"partition,omitempty"`
+	Key       string            `json:"key,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	Value     any               `json:"value"`
+	Error     string            `json:"error,omitempty"`
+}
+
+// Produce сериализует msg в JSON и публикует его в топик, объявленный типом T.
+// Для топиков с форматом protobuf или avro возвращается ErrUnsupportedFormat.
+func Produce[T KafkaMessage](sCtx provider.StepCtx, p *Producer, msg T, opts ...ProduceOption) error {
+	var options produceOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	topic := Topic(msg.TopicPart())
+	if decoder := decoderFor(p.cfg, topic); decoder != JSONDecoder {
+		return fmt.Errorf("%w: topic %s", ErrUnsupportedFormat, topic)
+	}
+
+	value, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("kafka: marshal %T: %w", msg, err)
+	}
+
+	message := kafka.Message{
+		Topic:   string(topic),
+		Key:     options.key,
+		Value:   value,
+		Headers: options.headers,
+	}
+
+	attachment := producedMessage{
+		Topic:     topic,
+		Partition: options.partition,
+		Key:       string(options.key),
+		Value:     msg,
+	}
+	if len(options.headers) > 0 {
+		attachment.Headers = make(map[string]string, len(options.headers))
+		for _, header := range options.headers {
+			attachment.Headers[header.Key] = string(header.Value)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	defer cancel()
+
+	if options.partition != nil {
+		err = p.writeToPartition(ctx, *options.partition, message)
+	} else {
+		err = p.writer.WriteMessages(ctx, message)
+	}
+	if err != nil {
+		attachment.Error = err.Error()
+		sCtx.WithAttachments(allure.NewAttachment("Kafka Produce Failed", allure.JSON, utils.CreatePrettyJSON(attachment)))
+		return fmt.Errorf("kafka: produce to %s: %w", topic, err)
+	}
+	sCtx.WithAttachments(allure.NewAttachment("Kafka Produced Message", allure.JSON, utils.CreatePrettyJSON(attachment)))
+
+	sCtx.Logf("Сообщение опубликовано в топик %s", topic)
+	return nil
+}
+
+func (p *Producer) writeToPartition(ctx context.Context, partition int, message kafka.Message) error {
+	conn, err := kafka.DialLeader(ctx, "tcp", p.brokers, message.Topic, partition)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetWriteDeadline(deadline); err != nil {
+			return err
+		}
+	}
+
+	message.Topic = ""
+	_, err = conn.WriteMessages(message)
+	return err
+}
+
+func (p *Producer) Close() error {
+	return p.writer.Close()
+}
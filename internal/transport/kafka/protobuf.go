@@ -0,0 +1,202 @@
+package kafka
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"CB_auto/internal/config"
+
+	"github.com/segmentio/kafka-go"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// ProtobufDecoder декодирует сообщения по FileDescriptorSet (protoc --descriptor_set_out --include_imports)
+// и выводит их через protojson: ключи из json_name или из имен полей при UseProtoNames,
+// NaN и Infinity — строковыми литералами. 64-битные целые protojson пишет строками; они переводятся
+// обратно в JSON-числа, чтобы сообщения разбирались в структуры с полями int и int64.
+type ProtobufDecoder struct {
+	message          protoreflect.MessageDescriptor
+	confluentFraming bool
+	unmarshal        proto.UnmarshalOptions
+	marshal          protojson.MarshalOptions
+}
+
+func newProtobufDecoder(cfg config.KafkaDecoderConfig) (Decoder, error) {
+	if cfg.DescriptorSet == "" || cfg.MessageType == "" {
+		return nil, fmt.Errorf("kafka: protobuf decoder requires descriptor_set and message_type")
+	}
+	data, err := os.ReadFile(cfg.DescriptorSet)
+	if err != nil {
+		return nil, fmt.Errorf("kafka: read descriptor set: %w", err)
+	}
+	return NewProtobufDecoder(data, cfg.MessageType, cfg.ConfluentFraming, cfg.UseProtoNames)
+}
+
+func NewProtobufDecoder(descriptorSet []byte, messageType string, confluentFraming, useProtoNames bool) (*ProtobufDecoder, error) {
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(descriptorSet, &set); err != nil {
+		return nil, fmt.Errorf("kafka: parse descriptor set: %w", err)
+	}
+	files, err := protodesc.NewFiles(&set)
+	if err != nil {
+		return nil, fmt.Errorf("kafka: parse descriptor set: %w", err)
+	}
+
+	name := protoreflect.FullName(strings.TrimPrefix(messageType, "."))
+	descriptor, err := files.FindDescriptorByName(name)
+	if err != nil {
+		return nil, fmt.Errorf("kafka: message type %s not found in descriptor set", name)
+	}
+	message, ok := descriptor.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("kafka: %s is not a message type", name)
+	}
+
+	// Типы из набора нужны для разбора вложенных google.protobuf.Any и расширений.
+	types := dynamicpb.NewTypes(files)
+	return &ProtobufDecoder{
+		message:          message,
+		confluentFraming: confluentFraming,
+		unmarshal:        proto.UnmarshalOptions{Resolver: types},
+		marshal:          protojson.MarshalOptions{UseProtoNames: useProtoNames, Resolver: types},
+	}, nil
+}
+
+func (d *ProtobufDecoder) Decode(msg kafka.Message) ([]byte, error) {
+	payload := msg.Value
+	if d.confluentFraming {
+		_, framed, err := splitConfluentFrame(payload)
+		if err != nil {
+			return nil, err
+		}
+		if payload, err = skipMessageIndexes(framed); err != nil {
+			return nil, err
+		}
+	}
+
+	message := dynamicpb.NewMessage(d.message)
+	if err := d.unmarshal.Unmarshal(payload, message); err != nil {
+		return nil, fmt.Errorf("protobuf: %s: %w", d.message.FullName(), err)
+	}
+	value, err := d.marshal.Marshal(message)
+	if err != nil {
+		return nil, fmt.Errorf("protobuf: %s: %w", d.message.FullName(), err)
+	}
+	return int64AsNumbers(value, d.message)
+}
+
+// int64AsNumbers заменяет строковые значения 64-битных целых полей (включая Int64Value и UInt64Value) на числа.
+func int64AsNumbers(value []byte, message protoreflect.MessageDescriptor) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(value))
+	decoder.UseNumber()
+	var tree any
+	if err := decoder.Decode(&tree); err != nil {
+		return nil, fmt.Errorf("protobuf: %s: %w", message.FullName(), err)
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(convertMessage(tree, message)); err != nil {
+		return nil, fmt.Errorf("protobuf: %s: %w", message.FullName(), err)
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+func convertMessage(value any, message protoreflect.MessageDescriptor) any {
+	if isInt64Wrapper(message) {
+		return int64Number(value)
+	}
+	object, ok := value.(map[string]any)
+	if !ok {
+		return value
+	}
+	fields := message.Fields()
+	for key, v := range object {
+		field := fields.ByJSONName(key)
+		if field == nil {
+			field = fields.ByName(protoreflect.Name(key))
+		}
+		if field != nil {
+			object[key] = convertField(v, field)
+		}
+	}
+	return object
+}
+
+func convertField(value any, field protoreflect.FieldDescriptor) any {
+	switch {
+	case field.IsMap():
+		if entries, ok := value.(map[string]any); ok {
+			for key, v := range entries {
+				entries[key] = convertValue(v, field.MapValue())
+			}
+		}
+	case field.IsList():
+		if items, ok := value.([]any); ok {
+			for i, v := range items {
+				items[i] = convertValue(v, field)
+			}
+		}
+	default:
+		return convertValue(value, field)
+	}
+	return value
+}
+
+func convertValue(value any, field protoreflect.FieldDescriptor) any {
+	switch field.Kind() {
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return int64Number(value)
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return convertMessage(value, field.Message())
+	}
+	return value
+}
+
+func isInt64Wrapper(message protoreflect.MessageDescriptor) bool {
+	name := message.FullName()
+	return name == "google.protobuf.Int64Value" || name == "google.protobuf.UInt64Value"
+}
+
+func int64Number(value any) any {
+	s, ok := value.(string)
+	if !ok {
+		return value
+	}
+	if _, err := strconv.ParseInt(s, 10, 64); err != nil {
+		if _, err := strconv.ParseUint(s, 10, 64); err != nil {
+			return value
+		}
+	}
+	return json.Number(s)
+}
+
+// skipMessageIndexes пропускает message indexes schema registry (zigzag varint: количество, затем индексы):
+// тип сообщения задан message_type.
+func skipMessageIndexes(data []byte) ([]byte, error) {
+	count, n := protowire.ConsumeVarint(data)
+	if n < 0 {
+		return nil, fmt.Errorf("protobuf: message indexes: %w", protowire.ParseError(n))
+	}
+	data = data[n:]
+	for i := protowire.DecodeZigZag(count); i > 0; i-- {
+		_, n := protowire.ConsumeVarint(data)
+		if n < 0 {
+			return nil, fmt.Errorf("protobuf: message indexes: %w", protowire.ParseError(n))
+		}
+		data = data[n:]
+	}
+	return data, nil
+}
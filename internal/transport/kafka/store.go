@@ -0,0 +1,240 @@
+package kafka
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// DefaultIndexFields — JSON-поля, по которым индексируются сообщения, если kafka.index_fields не задан.
+// Путь с точками считается от корня документа.
+var DefaultIndexFields = []string{
+	"player_uuid",
+	"playerId",
+	"playerUUID",
+	"wallet_uuid",
+	"brand.uuid",
+	"player.externalId",
+	"player.accountId",
+	"player.phone",
+	"player.email",
+}
+
+type storedMessage struct {
+	id       uint64
+	received time.Time
+	msg      kafka.Message
+}
+
+// Lookup сужает выборку сообщений по индексам хранилища. Пустые поля не участвуют в поиске.
+type Lookup struct {
+	Key         string
+	Field       string
+	FieldValue  string
+	Header      string
+	HeaderValue string
+}
+
+func (l Lookup) indexKey() (string, bool) {
+	switch {
+	case l.Key != "":
+		return "key\x00" + l.Key, true
+	case l.Field != "":
+		return "field\x00" + l.Field + "\x00" + l.FieldValue, true
+	case l.Header != "":
+		return "header\x00" + l.Header + "\x00" + l.HeaderValue, true
+	default:
+		return "", false
+	}
+}
+
+// MessageStore хранит сообщения одного топика с индексами по ключу, заголовкам и выбранным JSON-полям
+// (поля берутся из значения, приведенного к JSON декодером топика).
+// Сообщения удаляются по времени хранения retention и по количеству maxSize: действует более строгое ограничение.
+// Ожидающие поиски уведомляются о вставке через канал updated вместо отдельной подписки на каждый поиск.
+type MessageStore struct {
+	mu        sync.Mutex
+	decoder   Decoder
+	fields    []string
+	retention time.Duration
+	maxSize   int
+	lastID    uint64
+	messages  []*storedMessage
+	index     map[string][]*storedMessage
+	updated   chan struct{}
+	pruned    time.Time
+}
+
+func NewMessageStore(decoder Decoder, fields []string, retention time.Duration, maxSize int) *MessageStore {
+	return &MessageStore{
+		decoder:   decoder,
+		fields:    fields,
+		retention: retention,
+		maxSize:   maxSize,
+		index:     make(map[string][]*storedMessage),
+		updated:   make(chan struct{}),
+	}
+}
+
+func (s *MessageStore) Add(msg kafka.Message) {
+	// Декодер и список полей не меняются, поэтому ключи индекса считаются до захвата блокировки.
+	keys := s.indexKeys(msg)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastID++
+	stored := &storedMessage{id: s.lastID, received: time.Now(), msg: msg}
+	s.messages = append(s.messages, stored)
+	for _, key := range keys {
+		s.index[key] = append(s.index[key], stored)
+	}
+	s.prune(stored.received)
+
+	close(s.updated)
+	s.updated = make(chan struct{})
+}
+
+// Since возвращает сообщения с id больше after, подходящие под lookup, и канал, закрывающийся при следующей вставке.
+func (s *MessageStore) Since(after uint64, lookup Lookup) ([]*storedMessage, <-chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	source := s.messages
+	if key, ok := lookup.indexKey(); ok && s.indexed(lookup) {
+		source = s.index[key]
+	}
+
+	start := len(source)
+	for start > 0 && source[start-1].id > after {
+		start--
+	}
+	result := make([]*storedMessage, len(source)-start)
+	copy(result, source[start:])
+	return result, s.updated
+}
+
+// LastID возвращает id последнего сохраненного сообщения, чтобы поиск мог начать только с новых.
+func (s *MessageStore) LastID() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastID
+}
+
+func (s *MessageStore) Decoder() Decoder {
+	return s.decoder
+}
+
+func (s *MessageStore) All() []kafka.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	messages := make([]kafka.Message, len(s.messages))
+	for i, stored := range s.messages {
+		messages[i] = stored.msg
+	}
+	return messages
+}
+
+func (s *MessageStore) indexKeys(msg kafka.Message) []string {
+	var keys []string
+	if len(msg.Key) > 0 {
+		keys = append(keys, Lookup{Key: string(msg.Key)}.mustIndexKey())
+	}
+	for _, header := range msg.Headers {
+		keys = append(keys, Lookup{Header: header.Key, HeaderValue: string(header.Value)}.mustIndexKey())
+	}
+	if len(s.fields) == 0 {
+		return keys
+	}
+
+	value, err := s.decoder.Decode(msg)
+	if err != nil {
+		return keys
+	}
+	decoder := json.NewDecoder(bytes.NewReader(value))
+	decoder.UseNumber()
+	var doc map[string]any
+	if err := decoder.Decode(&doc); err != nil {
+		return keys
+	}
+	for _, field := range s.fields {
+		if value, ok := lookupField(doc, field); ok {
+			keys = append(keys, Lookup{Field: field, FieldValue: value}.mustIndexKey())
+		}
+	}
+	return keys
+}
+
+// indexed сообщает, ведется ли индекс для lookup: ключи и заголовки индексируются всегда, JSON-поля — только из fields.
+func (s *MessageStore) indexed(lookup Lookup) bool {
+	if lookup.Key != "" || lookup.Field == "" {
+		return true
+	}
+	return slices.Contains(s.fields, lookup.Field)
+}
+
+func (l Lookup) mustIndexKey() string {
+	key, _ := l.indexKey()
+	return key
+}
+
+func lookupField(doc map[string]any, path string) (string, bool) {
+	var node any = doc
+	for _, segment := range strings.Split(path, ".") {
+		object, ok := node.(map[string]any)
+		if !ok {
+			return "", false
+		}
+		if node, ok = object[segment]; !ok {
+			return "", false
+		}
+	}
+	switch value := node.(type) {
+	case nil, map[string]any, []any:
+		return "", false
+	default:
+		return fmt.Sprint(value), true
+	}
+}
+
+// prune удаляет сообщения старше retention и сверх maxSize. Чистка идет пачками: по времени не чаще раза в секунду,
+// по количеству — когда размер превышает maxSize на четверть.
+func (s *MessageStore) prune(now time.Time) {
+	drop := 0
+	if s.retention > 0 && now.Sub(s.pruned) >= time.Second {
+		s.pruned = now
+		cutoff := now.Add(-s.retention)
+		for drop < len(s.messages) && s.messages[drop].received.Before(cutoff) {
+			drop++
+		}
+	}
+	if s.maxSize > 0 && len(s.messages)-drop > s.maxSize+s.maxSize/4 {
+		drop = len(s.messages) - s.maxSize
+	}
+	if drop == 0 {
+		return
+	}
+
+	oldest := s.messages[drop-1].id
+	s.messages = append([]*storedMessage(nil), s.messages[drop:]...)
+	for key, entries := range s.index {
+		keep := 0
+		for keep < len(entries) && entries[keep].id <= oldest {
+			keep++
+		}
+		if keep == len(entries) {
+			delete(s.index, key)
+			continue
+		}
+		if keep > 0 {
+			s.index[key] = append([]*storedMessage(nil), entries[keep:]...)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package kafka
+
+import (
+	"sync"
+)
+
+type TopicType string
+type TopicPart string
+
+const (
+	BrandTopicPart      TopicPart = "core.gambling.v1.Brand"
+	PlayerTopicPart     TopicPart = "player.v1.account"
+	LimitTopicPart      TopicPart = "limits.v2"
+	ProjectionTopicPart TopicPart = "wallet.v8.projectionSource"
+	GameTopicPart       TopicPart = "core.gambling.v2.Game"
+	PaymentTopicPart    TopicPart = "payment.v1.transaction"
+)
+
+// KafkaMessage — сообщение, которое объявляет суффикс своего топика.
+// Полное имя топика строится реестром из kafka.topic_prefix и переопределений kafka.topics.
+type KafkaMessage interface {
+	TopicPart() TopicPart
+}
+
+type topicRegistry struct {
+	mu        sync.RWMutex
+	prefix    string
+	overrides map[string]string
+}
+
+var topics = &topicRegistry{}
+
+// ConfigureTopics задает префикс топиков и переопределения суффиксов вида {"limits.v2": "limits.v3"}.
+func ConfigureTopics(prefix string, overrides map[string]string) {
+	topics.mu.Lock()
+	topics.prefix = prefix
+	topics.overrides = overrides
+	topics.mu.Unlock()
+
+	TopicsConfig = NewTopics(prefix)
+}
+
+// Topic возвращает полное имя топика для суффикса.
+func Topic(part TopicPart) TopicType {
+	topics.mu.RLock()
+	defer topics.mu.RUnlock()
+	return topics.resolve(topics.prefix, part)
+}
+
+func (r *topicRegistry) resolve(prefix string, part TopicPart) TopicType {
+	if override, ok := r.overrides[string(part)]; ok {
+		return TopicType(prefix + override)
+	}
+	return TopicType(prefix + string(part))
+}
+
+// Topics — полные имена топиков, известных consumer до появления реестра.
+//
+// Deprecated: используйте Topic или GetTopicForType.
+type Topics struct {
+	Brand      TopicType
+	Player     TopicType
+	Limit      TopicType
+	Projection TopicType
+	Game       TopicType
+	Payment    TopicType
+}
+
+// NewTopics строит Topics для префикса с учетом переопределений kafka.topics.
+//
+// Deprecated: используйте Topic или GetTopicForType.
+func NewTopics(prefix string) Topics {
+	topics.mu.RLock()
+	defer topics.mu.RUnlock()
+	return Topics{
+		Brand:      topics.resolve(prefix, BrandTopicPart),
+		Player:     topics.resolve(prefix, PlayerTopicPart),
+		Limit:      topics.resolve(prefix, LimitTopicPart),
+		Projection: topics.resolve(prefix, ProjectionTopicPart),
+		Game:       topics.resolve(prefix, GameTopicPart),
+		Payment:    topics.resolve(prefix, PaymentTopicPart),
+	}
+}
+
+// TopicsConfig заполняется ConfigureTopics.
+//
+// Deprecated: используйте Topic или GetTopicForType.
+var TopicsConfig Topics
+
+// GetTopicForType возвращает топик, в который публикуются сообщения типа T.
+func GetTopicForType[T KafkaMessage]() TopicType {
+	var msg T
+	return Topic(msg.TopicPart())
+}
+
+func (m Brand) TopicPart() TopicPart {
+	return BrandTopicPart
+}
+
+func (m PlayerMessage) TopicPart() TopicPart {
+	return PlayerTopicPart
+}
+
+func (m LimitMessage) TopicPart() TopicPart {
+	return LimitTopicPart
+}
+
+func (m ProjectionSourceMessage) TopicPart() TopicPart {
+	return ProjectionTopicPart
+}
+
+func (m GameMessage) TopicPart() TopicPart {
+	return GameTopicPart
+}
+
+func (m TransactionMessage) TopicPart() TopicPart {
+	return PaymentTopicPart
+}
+
+// GetTopic возвращает полное имя топика сообщения.
+//
+// Deprecated: используйте GetTopicForType.
+func (m PlayerMessage) GetTopic() TopicType {
+	return Topic(m.TopicPart())
+}
+
+// Deprecated: используйте GetTopicForType.
+func (m LimitMessage) GetTopic() TopicType {
+	return Topic(m.TopicPart())
+}
+
+// Deprecated: используйте GetTopicForType.
+func (m ProjectionSourceMessage) GetTopic() TopicType {
+	return Topic(m.TopicPart())
+}
+
+// Deprecated: используйте GetTopicForType.
+func (m GameMessage) GetTopic() TopicType {
+	return Topic(m.TopicPart())
+}
+
+// Deprecated: используйте GetTopicForType.
+func (m TransactionMessage) GetTopic() TopicType {
+	return Topic(m.TopicPart())
+}
@@ -0,0 +1,74 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"CB_auto/pkg/utils"
+
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+)
+
+// AssertNoMessage проверяет, что в теме subject за период тишины quiet не появилось сообщений, прошедших filter.
+// Без опций проверяются только сообщения, опубликованные после вызова. Чтобы захватить и уже опубликованные,
+// например с момента действия, передается FromSequence (из CaptureSequence до действия) или FromTime.
+func AssertNoMessage[T any](sCtx provider.StepCtx, n *NatsClient, subject string, quiet time.Duration, filter func(data T, msgType string) bool, opts ...SearchOption) bool {
+	if len(opts) == 0 {
+		seq, err := n.CaptureSequence(subject)
+		if err != nil {
+			sCtx.Errorf("Ошибка при получении позиции стрима NATS: %v", err)
+			return false
+		}
+		opts = []SearchOption{FromSequence(seq)}
+	}
+
+	f, release, err := n.feedFor(subject, newSearchOptions(opts))
+	if err != nil {
+		sCtx.Errorf("Ошибка при подписке на NATS: %v", err)
+		return false
+	}
+	defer release()
+	sCtx.Logf("NATS ТИШИНА [%s]: Ожидание отсутствия сообщений в течение %v", subject, quiet)
+
+	n.wg.Add(1)
+	defer n.wg.Done()
+
+	ctx, cancel := context.WithTimeout(n.ctx, quiet)
+	defer cancel()
+
+	position := 0
+	for {
+		msgs, updated := f.since(position)
+		position += len(msgs)
+
+		for _, msg := range msgs {
+			if _, ok := matchSubject(subject, msg.Subject); !ok {
+				continue
+			}
+
+			var data T
+			if err := json.Unmarshal(msg.Data, &data); err != nil {
+				continue
+			}
+			if filter(data, msg.Header.Get("type")) {
+				found := newNatsMessage(msg, data)
+				sCtx.WithAttachments(allure.NewAttachment("Unexpected NATS Message", allure.JSON, utils.CreatePrettyJSON(found)))
+				sCtx.Errorf("NATS [%s]: Получено неожиданное сообщение %s (seq %d)", subject, found.Type, found.Seq)
+				return false
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			if n.ctx.Err() != nil {
+				sCtx.Errorf("NATS [%s]: Клиент закрыт до окончания периода тишины", subject)
+				return false
+			}
+			sCtx.Logf("NATS ТИШИНА [%s]: Подходящих сообщений не получено", subject)
+			return true
+		case <-updated:
+		}
+	}
+}
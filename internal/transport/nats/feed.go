@@ -0,0 +1,190 @@
+package nats
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+type searchOptions struct {
+	startSeq       uint64
+	startTime      time.Time
+	lastPerSubject bool
+}
+
+// SearchOption задает позицию, с которой JetStream начинает доставку сообщений для поиска.
+type SearchOption func(*searchOptions)
+
+// FromSequence начинает поиск с указанной последовательности стрима, например захваченной через CaptureSequence.
+func FromSequence(seq uint64) SearchOption {
+	return func(o *searchOptions) {
+		o.startSeq = seq
+	}
+}
+
+// FromTime начинает поиск с сообщений, опубликованных не раньше указанного времени.
+func FromTime(t time.Time) SearchOption {
+	return func(o *searchOptions) {
+		o.startTime = t
+	}
+}
+
+// LastPerSubject доставляет только последнее сообщение по каждой теме, подходящей под шаблон.
+func LastPerSubject() SearchOption {
+	return func(o *searchOptions) {
+		o.lastPerSubject = true
+	}
+}
+
+func newSearchOptions(opts []SearchOption) searchOptions {
+	var o searchOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+func (o searchOptions) key() string {
+	switch {
+	case o.lastPerSubject:
+		return "last"
+	case o.startSeq > 0:
+		return fmt.Sprintf("seq:%d", o.startSeq)
+	case !o.startTime.IsZero():
+		return "time:" + o.startTime.UTC().Format(time.RFC3339Nano)
+	default:
+		return "default"
+	}
+}
+
+// deliverPolicy без явной позиции читает стрим с начала, а при заданном nats.lookback — сообщения за последние lookback.
+// Второе значение описывает стартовую позицию для лога.
+func (o searchOptions) deliverPolicy(lookback time.Duration) (nats.SubOpt, string) {
+	switch {
+	case o.lastPerSubject:
+		return nats.DeliverLastPerSubject(), "последнее сообщение каждой темы"
+	case o.startSeq > 0:
+		return nats.StartSequence(o.startSeq), fmt.Sprintf("последовательность %d", o.startSeq)
+	case !o.startTime.IsZero():
+		return nats.StartTime(o.startTime), "время " + o.startTime.Format(time.RFC3339)
+	case lookback > 0:
+		start := time.Now().Add(-lookback)
+		return nats.StartTime(start), fmt.Sprintf("время %s (nats.lookback %v)", start.Format(time.RFC3339), lookback)
+	default:
+		return nats.DeliverAll(), "начало стрима"
+	}
+}
+
+// feedIdleTTL — сколько консьюмер без поисков живет до отписки. Поиски в тесте идут друг за другом,
+// поэтому feed переиспользуется следующими поисками, а не пересоздается с повторным чтением стрима.
+const feedIdleTTL = 10 * time.Minute
+
+// feed накапливает сообщения одного упорядоченного консьюмера, чтобы поиски
+// по той же теме и стартовой позиции не создавали отдельные подписки.
+// Консьюмер удаляется при закрытии клиента или через feedIdleTTL после завершения последнего поиска.
+type feed struct {
+	mu      sync.Mutex
+	msgs    []*nats.Msg
+	updated chan struct{}
+	sub     *nats.Subscription
+	refs    int
+	idle    *time.Timer
+}
+
+func newFeed() *feed {
+	return &feed{updated: make(chan struct{})}
+}
+
+func (f *feed) add(msg *nats.Msg) {
+	f.mu.Lock()
+	f.msgs = append(f.msgs, msg)
+	close(f.updated)
+	f.updated = make(chan struct{})
+	f.mu.Unlock()
+}
+
+// since возвращает сообщения начиная с позиции from и канал, закрывающийся при появлении новых.
+func (f *feed) since(from int) ([]*nats.Msg, <-chan struct{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.msgs[from:], f.updated
+}
+
+// feedFor возвращает feed для темы и стартовой позиции и функцию release, которую поиск вызывает по завершении.
+func (n *NatsClient) feedFor(subject string, o searchOptions) (*feed, func(), error) {
+	key := subject + "|" + o.key()
+
+	n.subsMutex.Lock()
+	defer n.subsMutex.Unlock()
+
+	f, ok := n.feeds[key]
+	if !ok {
+		stream, err := n.ResolveStream(subject)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		policy, start := o.deliverPolicy(n.lookback)
+		f = newFeed()
+		f.sub, err = n.js.Subscribe(subject, f.add,
+			nats.OrderedConsumer(),
+			nats.BindStream(stream),
+			nats.ReplayInstant(),
+			policy,
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+		log.Printf("NATS: консьюмер для %s в стриме %s читает с позиции: %s", subject, stream, start)
+		n.feeds[key] = f
+	}
+
+	if f.idle != nil {
+		f.idle.Stop()
+		f.idle = nil
+	}
+	f.refs++
+	return f, func() { n.releaseFeed(key, f) }, nil
+}
+
+// releaseFeed отмечает завершение поиска. Последний поиск не удаляет feed, а запускает таймер простоя.
+func (n *NatsClient) releaseFeed(key string, f *feed) {
+	n.subsMutex.Lock()
+	defer n.subsMutex.Unlock()
+
+	f.refs--
+	if f.refs > 0 || n.feeds[key] != f {
+		return
+	}
+	f.idle = time.AfterFunc(feedIdleTTL, func() { n.expireFeed(key, f) })
+}
+
+func (n *NatsClient) expireFeed(key string, f *feed) {
+	n.subsMutex.Lock()
+	defer n.subsMutex.Unlock()
+
+	if f.refs > 0 || n.feeds[key] != f {
+		return
+	}
+	delete(n.feeds, key)
+	if err := f.sub.Unsubscribe(); err != nil {
+		log.Printf("Ошибка при отписке от NATS: %v", err)
+	}
+}
+
+// CaptureSequence возвращает последовательность, с которой начнутся сообщения, опубликованные после вызова.
+// Значение передается в FromSequence, чтобы поиск не находил события прошлых запусков.
+func (n *NatsClient) CaptureSequence(subject string) (uint64, error) {
+	stream, err := n.ResolveStream(subject)
+	if err != nil {
+		return 0, err
+	}
+	info, err := n.js.StreamInfo(stream)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось получить информацию о стриме %s: %w", stream, err)
+	}
+	return info.State.LastSeq + 1, nil
+}
@@ -3,6 +3,7 @@ package nats
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log"
 	"strings"
 	"sync"
@@ -23,10 +24,15 @@ type NatsClient struct {
 	cancel    context.CancelFunc
 	wg        sync.WaitGroup
 	timeout   time.Duration
+	lookback  time.Duration
 	subsMutex sync.Mutex
-	subs      []*nats.Subscription
+	streams   *streamResolver
+	feeds     map[string]*feed
 }
 
+// defaultStreamTimeout используется, если nats.stream_timeout не задан.
+const defaultStreamTimeout = 30 * time.Second
+
 type NatsMessage[T any] struct {
 	Payload   T
 	Metadata  *nats.MsgMetadata
@@ -41,7 +47,7 @@ func NewClient(cfg *config.NatsConfig) *NatsClient {
 	log.Printf("Creating new NATS client: hosts=%s", cfg.Hosts)
 
 	nc, err := nats.Connect(cfg.Hosts,
-		nats.ReconnectWait(time.Duration(cfg.ReconnectWait)*time.Second),
+		nats.ReconnectWait(cfg.ReconnectWait),
 		nats.MaxReconnects(cfg.MaxReconnects))
 	if err != nil {
 		log.Printf("Ошибка подключения к NATS: %v", err)
@@ -54,84 +60,54 @@ func NewClient(cfg *config.NatsConfig) *NatsClient {
 
 	ctx, cancel := context.WithCancel(context.Background())
 
-	return &NatsClient{
-		conn:    nc,
-		js:      js,
-		ctx:     ctx,
-		cancel:  cancel,
-		timeout: cfg.StreamTimeout * time.Second,
+	timeout := cfg.StreamTimeout
+	if timeout <= 0 {
+		timeout = defaultStreamTimeout
 	}
-}
-
-func (c *NatsClient) subscribeWithDeliverAll(subject string) (chan *nats.Msg, *nats.Subscription, error) {
-	msgCh := make(chan *nats.Msg, 100)
-
-	opts := []nats.SubOpt{
-		nats.DeliverAll(),
-		nats.AckExplicit(),
-		nats.ReplayInstant(),
-		nats.StartSequence(1),
-		nats.BindStream("beta-09_wallet"),
-	}
-
-	sub, err := c.js.Subscribe(subject, func(msg *nats.Msg) {
-		msgCh <- msg
-	}, opts...)
-	if err != nil {
-		return nil, nil, err
+	return &NatsClient{
+		conn:     nc,
+		js:       js,
+		ctx:      ctx,
+		cancel:   cancel,
+		timeout:  timeout,
+		lookback: cfg.Lookback,
+		streams:  newStreamResolver(js, cfg.StreamPrefix, cfg.Streams),
+		feeds:    make(map[string]*feed),
 	}
-
-	c.subsMutex.Lock()
-	c.subs = append(c.subs, sub)
-	c.subsMutex.Unlock()
-	return msgCh, sub, nil
 }
 
-func FindMessageInStream[T any](sCtx provider.StepCtx, n *NatsClient, subject string, filter func(data T, msgType string) bool) *NatsMessage[T] {
-	msgCh, sub, err := n.subscribeWithDeliverAll(subject)
+// FindMessageInStream ищет первое сообщение темы subject, прошедшее filter.
+// По умолчанию стрим читается с начала; если задан nats.lookback, — только сообщения за последние lookback.
+// FromSequence (например, из CaptureSequence), FromTime и LastPerSubject задают позицию явно.
+// Консьюмер переиспользуется следующими поисками с той же темой и позицией, так что стрим читается один раз.
+func FindMessageInStream[T any](sCtx provider.StepCtx, n *NatsClient, subject string, filter func(data T, msgType string) bool, opts ...SearchOption) *NatsMessage[T] {
+	f, release, err := n.feedFor(subject, newSearchOptions(opts))
 	if err != nil {
 		sCtx.Errorf("Ошибка при подписке на NATS: %v", err)
 		return nil
 	}
+	defer release()
 	sCtx.Logf("NATS ПОИСК: Подписались на шаблон: %s", subject)
 
 	n.wg.Add(1)
 	defer n.wg.Done()
-	defer sub.Unsubscribe()
 
 	ctx, cancel := context.WithTimeout(n.ctx, n.timeout)
 	defer cancel()
 
+	position := 0
 	for {
-		select {
-		case <-ctx.Done():
-			sCtx.Errorf("Timeout waiting for message on subject %s", subject)
-			return nil
-		case msg, ok := <-msgCh:
-			if !ok {
-				sCtx.Errorf("Канал сообщений закрыт для темы %s", subject)
-				return nil
-			}
+		msgs, updated := f.since(position)
+		position += len(msgs)
 
+		for _, msg := range msgs {
 			sCtx.Logf("NATS ПОИСК [%s]: Получено сообщение с темой: %s", subject, msg.Subject)
 
-			subjectParts := strings.Split(msg.Subject, ".")
-			templateParts := strings.Split(subject, ".")
-			if len(subjectParts) != len(templateParts) {
-				sCtx.Logf("NATS ПОИСК [%s]: Пропуск - разное количество частей в теме", subject)
+			if reason, ok := matchSubject(subject, msg.Subject); !ok {
+				sCtx.Logf("NATS ПОИСК [%s]: Пропуск - %s", subject, reason)
 				continue
 			}
 
-			if len(subjectParts) > 3 && len(templateParts) > 3 {
-				subjectUUID := subjectParts[3]
-				templateUUID := templateParts[3]
-				if templateUUID != "*" && subjectUUID != templateUUID {
-					sCtx.Logf("NATS ПОИСК [%s]: Пропуск - UUID игрока не совпадает: %s != %s",
-						subject, templateUUID, subjectUUID)
-					continue
-				}
-			}
-
 			sCtx.Logf("NATS ПОИСК [%s]: Сообщение прошло проверку темы: %s", subject, msg.Subject)
 
 			var data T
@@ -142,36 +118,69 @@ func FindMessageInStream[T any](sCtx provider.StepCtx, n *NatsClient, subject st
 
 			if filter(data, msg.Header.Get("type")) {
 				sCtx.Logf("NATS ПОИСК [%s]: Сообщение прошло фильтр данных", subject)
-				meta, _ := msg.Metadata()
 				sCtx.WithAttachments(allure.NewAttachment("NATS Message", allure.JSON, utils.CreatePrettyJSON(data)))
-				return &NatsMessage[T]{
-					Payload:   data,
-					Metadata:  meta,
-					Subject:   msg.Subject,
-					Sequence:  int(meta.Sequence.Stream),
-					Seq:       meta.Sequence.Stream,
-					Timestamp: meta.Timestamp,
-					Type:      msg.Header.Get("type"),
-				}
+				return newNatsMessage(msg, data)
 			} else {
 				sCtx.Logf("NATS ПОИСК [%s]: Сообщение НЕ прошло фильтр данных", subject)
 			}
 		}
+
+		select {
+		case <-ctx.Done():
+			sCtx.Errorf("Timeout waiting for message on subject %s", subject)
+			return nil
+		case <-updated:
+		}
 	}
 }
 
+// matchSubject проверяет число сегментов темы и UUID игрока, если он задан в шаблоне.
+func matchSubject(template, subject string) (string, bool) {
+	subjectParts := strings.Split(subject, ".")
+	templateParts := strings.Split(template, ".")
+	if len(subjectParts) != len(templateParts) {
+		return "разное количество частей в теме", false
+	}
+
+	if len(subjectParts) > 3 && len(templateParts) > 3 {
+		subjectUUID := subjectParts[3]
+		templateUUID := templateParts[3]
+		if templateUUID != "*" && subjectUUID != templateUUID {
+			return fmt.Sprintf("UUID игрока не совпадает: %s != %s", templateUUID, subjectUUID), false
+		}
+	}
+	return "", true
+}
+
+func newNatsMessage[T any](msg *nats.Msg, data T) *NatsMessage[T] {
+	result := &NatsMessage[T]{
+		Payload: data,
+		Subject: msg.Subject,
+		Type:    msg.Header.Get("type"),
+	}
+	if meta, err := msg.Metadata(); err == nil {
+		result.Metadata = meta
+		result.Sequence = int(meta.Sequence.Stream)
+		result.Seq = meta.Sequence.Stream
+		result.Timestamp = meta.Timestamp
+	}
+	return result
+}
+
 func (n *NatsClient) Close() {
 	n.cancel()
 
 	n.wg.Wait()
 
 	n.subsMutex.Lock()
-	for _, sub := range n.subs {
-		if sub != nil {
-			if err := sub.Unsubscribe(); err != nil {
-				log.Printf("Ошибка при отписке от NATS: %v", err)
-			}
+	for key, f := range n.feeds {
+		if f.idle != nil {
+			f.idle.Stop()
+		}
+		if err := f.sub.Unsubscribe(); err != nil {
+			log.Printf("Ошибка при отписке от NATS: %v", err)
 		}
+		delete(n.feeds, key)
 	}
 	n.subsMutex.Unlock()
 
@@ -0,0 +1,108 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"CB_auto/pkg/utils"
+
+	"github.com/nats-io/nats.go"
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+)
+
+// WalletSubject строит тему события кошелька: <prefix>.wallet.<segment>.<player_uuid>.<wallet_uuid>.
+func (n *NatsClient) WalletSubject(segment, playerUUID, walletUUID string) string {
+	return fmt.Sprintf("%s.%s.%s.%s.%s", n.streams.prefix, WalletStream, segment, playerUUID, walletUUID)
+}
+
+type publishOptions struct {
+	msgID   string
+	headers nats.Header
+}
+
+// PublishOption настраивает отдельную публикацию.
+type PublishOption func(*publishOptions)
+
+// WithMsgID задает Nats-Msg-Id для дедупликации на стороне JetStream.
+func WithMsgID(id string) PublishOption {
+	return func(o *publishOptions) {
+		o.msgID = id
+	}
+}
+
+func WithHeader(key, value string) PublishOption {
+	return func(o *publishOptions) {
+		if o.headers == nil {
+			o.headers = nats.Header{}
+		}
+		o.headers.Add(key, value)
+	}
+}
+
+type publishedMessage struct {
+	Subject string      `json:"subject"`
+	Type    EventType   `json:"type"`
+	Headers nats.Header `json:"headers,omitempty"`
+	Stream  string      `json:"stream,omitempty"`
+	Seq     uint64      `json:"seq,omitempty"`
+	Payload any         `json:"payload"`
+}
+
+// Publish сериализует payload и публикует его в JetStream с заголовком type, дожидаясь подтверждения.
+func Publish[T any](sCtx provider.StepCtx, n *NatsClient, subject string, eventType EventType, payload T, opts ...PublishOption) (*nats.PubAck, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("nats: marshal %T: %w", payload, err)
+	}
+	return n.PublishRaw(sCtx, subject, eventType, data, opts...)
+}
+
+// PublishRaw публикует данные как есть, например намеренно некорректный JSON.
+func (n *NatsClient) PublishRaw(sCtx provider.StepCtx, subject string, eventType EventType, data []byte, opts ...PublishOption) (*nats.PubAck, error) {
+	var options publishOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	msg := nats.NewMsg(subject)
+	msg.Data = data
+	for key, values := range options.headers {
+		for _, value := range values {
+			msg.Header.Add(key, value)
+		}
+	}
+	msg.Header.Set("type", string(eventType))
+
+	var pubOpts []nats.PubOpt
+	if options.msgID != "" {
+		pubOpts = append(pubOpts, nats.MsgId(options.msgID))
+	}
+
+	ctx, cancel := context.WithTimeout(n.ctx, n.timeout)
+	defer cancel()
+	pubOpts = append(pubOpts, nats.Context(ctx))
+
+	attachment := publishedMessage{
+		Subject: subject,
+		Type:    eventType,
+		Headers: options.headers,
+		Payload: json.RawMessage(data),
+	}
+	if !json.Valid(data) {
+		attachment.Payload = string(data)
+	}
+
+	ack, err := n.js.PublishMsg(msg, pubOpts...)
+	if err != nil {
+		sCtx.WithAttachments(allure.NewAttachment("NATS Published Message", allure.JSON, utils.CreatePrettyJSON(attachment)))
+		return nil, fmt.Errorf("nats: publish %s to %s: %w", eventType, subject, err)
+	}
+
+	attachment.Stream = ack.Stream
+	attachment.Seq = ack.Sequence
+	sCtx.WithAttachments(allure.NewAttachment("NATS Published Message", allure.JSON, utils.CreatePrettyJSON(attachment)))
+	sCtx.Logf("NATS: Опубликовано событие %s в %s (stream %s, seq %d)", eventType, subject, ack.Stream, ack.Sequence)
+	return ack, nil
+}
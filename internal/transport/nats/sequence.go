@@ -0,0 +1,175 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"CB_auto/pkg/utils"
+
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+)
+
+// CollectMessages собирает все сообщения темы subject, прошедшие filter, в течение окна window.
+// Если window не задан, используется таймаут клиента. Сообщения возвращаются в порядке стрима.
+func CollectMessages[T any](sCtx provider.StepCtx, n *NatsClient, subject string, window time.Duration, filter func(data T, msgType string) bool, opts ...SearchOption) []*NatsMessage[T] {
+	return CollectMessagesUntil(sCtx, n, subject, window, filter, nil, opts...)
+}
+
+// CollectMessagesUntil работает как CollectMessages, но завершает сбор досрочно, как только done вернет true.
+func CollectMessagesUntil[T any](sCtx provider.StepCtx, n *NatsClient, subject string, window time.Duration, filter func(data T, msgType string) bool, done func(collected []*NatsMessage[T]) bool, opts ...SearchOption) []*NatsMessage[T] {
+	f, release, err := n.feedFor(subject, newSearchOptions(opts))
+	if err != nil {
+		sCtx.Errorf("Ошибка при подписке на NATS: %v", err)
+		return nil
+	}
+	defer release()
+	if window <= 0 {
+		window = n.timeout
+	}
+	sCtx.Logf("NATS СБОР [%s]: Сбор сообщений в течение %v", subject, window)
+
+	n.wg.Add(1)
+	defer n.wg.Done()
+
+	ctx, cancel := context.WithTimeout(n.ctx, window)
+	defer cancel()
+
+	var collected []*NatsMessage[T]
+	position := 0
+	for {
+		msgs, updated := f.since(position)
+		position += len(msgs)
+
+		for _, msg := range msgs {
+			if _, ok := matchSubject(subject, msg.Subject); !ok {
+				continue
+			}
+
+			var data T
+			if err := json.Unmarshal(msg.Data, &data); err != nil {
+				sCtx.Logf("NATS СБОР [%s]: Ошибка распаковки JSON: %v", subject, err)
+				continue
+			}
+			if filter(data, msg.Header.Get("type")) {
+				collected = append(collected, newNatsMessage(msg, data))
+			}
+		}
+
+		if done != nil && done(collected) {
+			sCtx.Logf("NATS СБОР [%s]: Условие выполнено досрочно, собрано сообщений: %d", subject, len(collected))
+			sCtx.WithAttachments(allure.NewAttachment("NATS Messages", allure.JSON, utils.CreatePrettyJSON(collected)))
+			return collected
+		}
+
+		select {
+		case <-ctx.Done():
+			sCtx.Logf("NATS СБОР [%s]: Собрано сообщений: %d", subject, len(collected))
+			sCtx.WithAttachments(allure.NewAttachment("NATS Messages", allure.JSON, utils.CreatePrettyJSON(collected)))
+			return collected
+		case <-updated:
+		}
+	}
+}
+
+type sequenceEvent struct {
+	Seq     uint64 `json:"seq"`
+	Type    string `json:"type"`
+	Subject string `json:"subject"`
+}
+
+// SequenceReport описывает расхождения между ожидаемой и фактической последовательностью событий.
+type SequenceReport struct {
+	Expected   []EventType     `json:"expected"`
+	Actual     []sequenceEvent `json:"actual"`
+	Missing    []EventType     `json:"missing,omitempty"`
+	Extra      []sequenceEvent `json:"extra,omitempty"`
+	Duplicates []sequenceEvent `json:"duplicates,omitempty"`
+	OutOfOrder []sequenceEvent `json:"out_of_order,omitempty"`
+}
+
+func (r SequenceReport) OK() bool {
+	return len(r.Missing) == 0 && len(r.Extra) == 0 && len(r.Duplicates) == 0 && len(r.OutOfOrder) == 0
+}
+
+// AssertEventSequence проверяет, что за окно window в теме subject появились ровно события expected
+// в указанном порядке с возрастающим Seq. Пропуски, дубликаты и лишние события отмечаются в allure-шаге.
+// Ожидание завершается, как только получена вся последовательность expected без расхождений.
+func AssertEventSequence(sCtx provider.StepCtx, n *NatsClient, subject string, expected []EventType, window time.Duration, opts ...SearchOption) []*NatsMessage[json.RawMessage] {
+	messages := CollectMessagesUntil(sCtx, n, subject, window, func(json.RawMessage, string) bool {
+		return true
+	}, func(collected []*NatsMessage[json.RawMessage]) bool {
+		return len(collected) >= len(expected) && buildSequenceReport(expected, collected).OK()
+	}, opts...)
+
+	report := buildSequenceReport(expected, messages)
+	sCtx.WithAttachments(allure.NewAttachment("NATS Event Sequence", allure.JSON, utils.CreatePrettyJSON(report)))
+
+	for _, missing := range report.Missing {
+		sCtx.Errorf("NATS [%s]: Не получено ожидаемое событие %s", subject, missing)
+	}
+	for _, extra := range report.Extra {
+		sCtx.Errorf("NATS [%s]: Лишнее событие %s (seq %d)", subject, extra.Type, extra.Seq)
+	}
+	for _, duplicate := range report.Duplicates {
+		sCtx.Errorf("NATS [%s]: Повторное событие %s (seq %d)", subject, duplicate.Type, duplicate.Seq)
+	}
+	for _, event := range report.OutOfOrder {
+		sCtx.Errorf("NATS [%s]: Нарушен порядок на событии %s (seq %d)", subject, event.Type, event.Seq)
+	}
+	return messages
+}
+
+// buildSequenceReport сопоставляет события с expected по типу: повтор типа сверх ожидаемого числа — дубликат,
+// ожидаемый тип не на своем месте — нарушение порядка, тип, которого нет в expected, — лишнее событие.
+func buildSequenceReport(expected []EventType, messages []*NatsMessage[json.RawMessage]) SequenceReport {
+	report := SequenceReport{Expected: expected}
+
+	want := make(map[EventType]int, len(expected))
+	for _, eventType := range expected {
+		want[eventType]++
+	}
+	got := make(map[EventType]int, len(expected))
+
+	var lastSeq uint64
+	next := 0
+	for _, msg := range messages {
+		event := sequenceEvent{Seq: msg.Seq, Type: msg.Type, Subject: msg.Subject}
+		report.Actual = append(report.Actual, event)
+		eventType := EventType(msg.Type)
+
+		if msg.Seq <= lastSeq {
+			report.OutOfOrder = append(report.OutOfOrder, event)
+			continue
+		}
+		lastSeq = msg.Seq
+
+		// Пропускаем ожидаемые позиции, уже закрытые событиями, пришедшими раньше своей очереди.
+		for next < len(expected) && got[expected[next]] >= want[expected[next]] {
+			next++
+		}
+
+		switch {
+		case want[eventType] == 0:
+			report.Extra = append(report.Extra, event)
+		case got[eventType] >= want[eventType]:
+			report.Duplicates = append(report.Duplicates, event)
+		case next < len(expected) && eventType == expected[next]:
+			got[eventType]++
+			next++
+		default:
+			got[eventType]++
+			report.OutOfOrder = append(report.OutOfOrder, event)
+		}
+	}
+
+	for _, eventType := range expected {
+		if got[eventType] > 0 {
+			got[eventType]--
+			continue
+		}
+		report.Missing = append(report.Missing, eventType)
+	}
+	return report
+}
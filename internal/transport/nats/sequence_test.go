@@ -0,0 +1,79 @@
+package nats
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func sequenceMessages(types ...EventType) []*NatsMessage[json.RawMessage] {
+	messages := make([]*NatsMessage[json.RawMessage], len(types))
+	for i, eventType := range types {
+		messages[i] = &NatsMessage[json.RawMessage]{Seq: uint64(i + 1), Type: string(eventType)}
+	}
+	return messages
+}
+
+func eventTypes(events []sequenceEvent) []EventType {
+	var result []EventType
+	for _, event := range events {
+		result = append(result, EventType(event.Type))
+	}
+	return result
+}
+
+func TestBuildSequenceReport(t *testing.T) {
+	expected := []EventType{WalletCreatedType, BlockAmountStartedType, BalanceAdjustedType}
+
+	tests := []struct {
+		name       string
+		actual     []EventType
+		missing    []EventType
+		extra      []EventType
+		duplicates []EventType
+		outOfOrder []EventType
+	}{
+		{
+			name:   "exact sequence",
+			actual: []EventType{WalletCreatedType, BlockAmountStartedType, BalanceAdjustedType},
+		},
+		{
+			name:    "missing tail",
+			actual:  []EventType{WalletCreatedType, BlockAmountStartedType},
+			missing: []EventType{BalanceAdjustedType},
+		},
+		{
+			name:       "repeated event type",
+			actual:     []EventType{WalletCreatedType, WalletCreatedType, BlockAmountStartedType, BalanceAdjustedType},
+			duplicates: []EventType{WalletCreatedType},
+		},
+		{
+			name:       "swapped events",
+			actual:     []EventType{WalletCreatedType, BalanceAdjustedType, BlockAmountStartedType},
+			outOfOrder: []EventType{BalanceAdjustedType},
+		},
+		{
+			name:   "unexpected event type",
+			actual: []EventType{WalletCreatedType, DepositedMoneyType, BlockAmountStartedType, BalanceAdjustedType},
+			extra:  []EventType{DepositedMoneyType},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := buildSequenceReport(expected, sequenceMessages(tt.actual...))
+
+			if !reflect.DeepEqual(report.Missing, tt.missing) {
+				t.Errorf("Missing = %v, want %v", report.Missing, tt.missing)
+			}
+			if got := eventTypes(report.Extra); !reflect.DeepEqual(got, tt.extra) {
+				t.Errorf("Extra = %v, want %v", got, tt.extra)
+			}
+			if got := eventTypes(report.Duplicates); !reflect.DeepEqual(got, tt.duplicates) {
+				t.Errorf("Duplicates = %v, want %v", got, tt.duplicates)
+			}
+			if got := eventTypes(report.OutOfOrder); !reflect.DeepEqual(got, tt.outOfOrder) {
+				t.Errorf("OutOfOrder = %v, want %v", got, tt.outOfOrder)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package nats
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/nats-io/nats.go"
+)
+
+type StreamDomain string
+
+const (
+	WalletStream StreamDomain = "wallet"
+	LimitsStream StreamDomain = "limits"
+	BonusStream  StreamDomain = "bonus"
+)
+
+// streamResolver определяет JetStream-стрим по теме подписки.
+// Порядок: явное имя из config.Nats.Streams, поиск стрима по теме через JetStream API,
+// имя по соглашению <stream_prefix>_<domain>.
+type streamResolver struct {
+	mu        sync.Mutex
+	js        nats.JetStreamContext
+	prefix    string
+	overrides map[string]string
+	cache     map[string]string
+}
+
+func newStreamResolver(js nats.JetStreamContext, prefix string, overrides map[string]string) *streamResolver {
+	return &streamResolver{
+		js:        js,
+		prefix:    prefix,
+		overrides: overrides,
+		cache:     make(map[string]string),
+	}
+}
+
+// StreamName возвращает имя стрима для домена по соглашению <stream_prefix>_<domain>.
+func (n *NatsClient) StreamName(domain StreamDomain) string {
+	if name, ok := n.streams.overrides[string(domain)]; ok {
+		return name
+	}
+	return fmt.Sprintf("%s_%s", n.streams.prefix, domain)
+}
+
+// ResolveStream возвращает имя стрима, в который публикуются сообщения темы subject.
+func (n *NatsClient) ResolveStream(subject string) (string, error) {
+	return n.streams.resolve(subject)
+}
+
+func (r *streamResolver) resolve(subject string) (string, error) {
+	domain := r.domain(subject)
+	if name, ok := r.overrides[domain]; ok {
+		return name, nil
+	}
+
+	// Темы одного домена отличаются только идентификаторами игроков и лежат в одном стриме,
+	// поэтому кэш хранится по домену, а по полной теме — только если домен не определен.
+	key := domain
+	if key == "" {
+		key = subject
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if name, ok := r.cache[key]; ok {
+		return name, nil
+	}
+
+	name, err := r.js.StreamNameBySubject(subject)
+	if err != nil {
+		if domain == "" {
+			return "", fmt.Errorf("не удалось определить стрим для темы %s: %w", subject, err)
+		}
+		name = fmt.Sprintf("%s_%s", r.prefix, domain)
+		log.Printf("Стрим для темы %s не найден через JetStream (%v), используется %s", subject, err, name)
+	}
+
+	r.cache[key] = name
+	return name, nil
+}
+
+// domain извлекает сегмент темы после префикса: "<prefix>.wallet.*.<player>.*" -> "wallet".
+func (r *streamResolver) domain(subject string) string {
+	rest, ok := strings.CutPrefix(subject, r.prefix+".")
+	if !ok {
+		return ""
+	}
+	domain, _, _ := strings.Cut(rest, ".")
+	if domain == "*" || domain == ">" {
+		return ""
+	}
+	return domain
+}
@@ -0,0 +1,12 @@
+package redis
+
+// Схема ключей Redis. Проекции хранятся под UUID без префиксов:
+// в player-базе — карта кошельков игрока, в wallet-базе — агрегат кошелька.
+
+func PlayerWalletsKey(playerUUID string) string {
+	return playerUUID
+}
+
+func WalletAggregateKey(walletUUID string) string {
+	return walletUUID
+}
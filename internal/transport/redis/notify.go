@@ -0,0 +1,111 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	redis "github.com/redis/go-redis/v9"
+)
+
+// keyWatcher будит ожидающих по keyspace-уведомлениям Redis (__keyspace@<db>__:<key>).
+// Подписка оформляется только на ключи, которые сейчас ждут, и снимается, когда ожидание последнего из них завершено.
+type keyWatcher struct {
+	pubsub  *redis.PubSub
+	prefix  string
+	mu      sync.Mutex
+	watches map[string]*keyWatch
+}
+
+// keyWatch — канал текущего ожидания ключа: закрывается при изменении ключа и сразу заменяется новым.
+type keyWatch struct {
+	changed chan struct{}
+	refs    int
+}
+
+// newKeyWatcher готовит подписку на изменения ключей базы db. Если на сервере не включены
+// keyspace-уведомления (notify-keyspace-events без K и A/$), возвращает ошибку, и ожидание остается опросом.
+func newKeyWatcher(client *redis.Client, db int) (*keyWatcher, error) {
+	ctx := context.Background()
+
+	settings, err := client.ConfigGet(ctx, "notify-keyspace-events").Result()
+	if err != nil {
+		return nil, fmt.Errorf("redis: failed to read notify-keyspace-events: %w", err)
+	}
+	flags := settings["notify-keyspace-events"]
+	if !strings.Contains(flags, "K") || !strings.ContainsAny(flags, "A$") {
+		return nil, fmt.Errorf("redis: keyspace notifications are disabled (notify-keyspace-events=%q)", flags)
+	}
+
+	w := &keyWatcher{
+		pubsub:  client.Subscribe(ctx),
+		prefix:  fmt.Sprintf("__keyspace@%d__:", db),
+		watches: make(map[string]*keyWatch),
+	}
+	go w.run()
+	return w, nil
+}
+
+func (w *keyWatcher) run() {
+	for msg := range w.pubsub.Channel() {
+		key := strings.TrimPrefix(msg.Channel, w.prefix)
+
+		w.mu.Lock()
+		if watch, ok := w.watches[key]; ok {
+			close(watch.changed)
+			watch.changed = make(chan struct{})
+		}
+		w.mu.Unlock()
+	}
+}
+
+// Watch подписывается на изменения key. Возвращенную функцию нужно вызвать по окончании ожидания:
+// после последнего вызова подписка на ключ снимается.
+func (w *keyWatcher) Watch(key string) (func(), error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	watch, ok := w.watches[key]
+	if !ok {
+		if err := w.pubsub.Subscribe(context.Background(), w.prefix+key); err != nil {
+			return nil, fmt.Errorf("redis: failed to subscribe to keyspace notifications for %s: %w", key, err)
+		}
+		watch = &keyWatch{changed: make(chan struct{})}
+		w.watches[key] = watch
+	}
+	watch.refs++
+
+	return func() { w.release(key) }, nil
+}
+
+func (w *keyWatcher) release(key string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	watch, ok := w.watches[key]
+	if !ok {
+		return
+	}
+	if watch.refs--; watch.refs > 0 {
+		return
+	}
+	delete(w.watches, key)
+	w.pubsub.Unsubscribe(context.Background(), w.prefix+key)
+}
+
+// Changed возвращает канал, который закроется при следующем изменении key, или nil, если key не отслеживается.
+// Канал нужно получить до чтения ключа, чтобы не пропустить изменение между чтением и ожиданием.
+func (w *keyWatcher) Changed(key string) <-chan struct{} {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if watch, ok := w.watches[key]; ok {
+		return watch.changed
+	}
+	return nil
+}
+
+func (w *keyWatcher) Close() error {
+	return w.pubsub.Close()
+}
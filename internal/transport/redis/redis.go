@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"CB_auto/internal/config"
+	"CB_auto/pkg/redact"
 	"CB_auto/pkg/utils"
 
 	"github.com/ozontech/allure-go/pkg/allure"
@@ -29,6 +30,9 @@ type RedisClient struct {
 	client        *redis.Client
 	retryAttempts int
 	retryDelay    time.Duration
+	maxRetryDelay time.Duration
+	waitTimeout   time.Duration
+	watcher       *keyWatcher
 }
 
 func NewRedisClient(t provider.T, cfg *config.RedisConfig, clientType RedisClientType) *RedisClient {
@@ -50,20 +54,33 @@ func NewRedisClient(t provider.T, cfg *config.RedisConfig, clientType RedisClien
 		Addr:         addr,
 		Password:     cfg.Password,
 		DB:           db,
-		DialTimeout:  time.Duration(cfg.DialTimeout) * time.Second,
-		ReadTimeout:  time.Duration(cfg.ReadTimeout) * time.Second,
-		WriteTimeout: time.Duration(cfg.WriteTimeout) * time.Second,
+		DialTimeout:  cfg.DialTimeout,
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
 	})
 
 	if err := client.Ping(context.Background()).Err(); err != nil {
 		t.Fatalf("Ошибка подключения к Redis (%s): %v", clientType, err)
 	}
 
-	return &RedisClient{
+	r := &RedisClient{
 		client:        client,
 		retryAttempts: cfg.RetryAttempts,
-		retryDelay:    time.Duration(cfg.RetryDelay) * time.Second,
+		retryDelay:    cfg.RetryDelay,
+		maxRetryDelay: cfg.MaxRetryDelay,
+		waitTimeout:   cfg.WaitTimeout,
 	}
+
+	if cfg.Notifications {
+		watcher, err := newKeyWatcher(client, db)
+		if err != nil {
+			log.Printf("Keyspace-уведомления Redis (%s) недоступны, ожидание будет опросом: %v", clientType, err)
+		} else {
+			r.watcher = watcher
+		}
+	}
+
+	return r
 }
 
 func (r *RedisClient) Get(key string) (string, error) {
@@ -77,85 +94,67 @@ func (r *RedisClient) Get(key string) (string, error) {
 		return "", fmt.Errorf("redis get failed: %v", err)
 	}
 
-	log.Printf("Redis value for key %s: %s", key, val)
+	log.Printf("Redis value for key %s: %s", key, redact.JSON([]byte(val)))
 	return val, nil
 }
 
 func (r *RedisClient) GetWithRetry(sCtx provider.StepCtx, key string, result interface{}) error {
-	var lastErr error
-	delay := r.retryDelay
-
-	for i := 0; i < r.retryAttempts; i++ {
-		value, err := r.Get(key)
-		if err == nil && value != "" {
-			if err := json.Unmarshal([]byte(value), result); err != nil {
-				log.Printf("Failed to unmarshal Redis value: %v", err)
-				lastErr = err
-				continue
-			}
-
-			sCtx.WithAttachments(allure.NewAttachment("Redis Value", allure.JSON, utils.CreatePrettyJSON(result)))
-			return nil
+	err := r.poll(key, r.newWaitOptions(nil), func(value string) (bool, error) {
+		if err := json.Unmarshal([]byte(value), result); err != nil {
+			log.Printf("Failed to unmarshal Redis value: %v", err)
+			return false, err
 		}
-		lastErr = err
-		log.Printf("Attempt %d: Redis key %s not found or empty, retrying in %v...", i+1, key, delay)
-		time.Sleep(delay)
-		delay *= 2
+		return true, nil
+	})
+	if err != nil {
+		log.Printf("Не удалось получить значение из Redis: %v", err)
+		return err
 	}
 
-	log.Printf("Не удалось получить значение из Redis после %d попыток: %v", r.retryAttempts, lastErr)
-	return lastErr
+	sCtx.WithAttachments(allure.NewAttachment("Redis Value", allure.JSON, utils.CreatePrettyJSON(result)))
+	return nil
 }
 
 func (r *RedisClient) GetWithSeqCheck(sCtx provider.StepCtx, key string, result interface{}, expectedSeq int) error {
-	var lastErr error
-	delay := r.retryDelay
-	maxAttempts := r.retryAttempts
-
-	for i := 0; i < maxAttempts; i++ {
-		value, err := r.Get(key)
-		if err == nil && value != "" {
-			if err := json.Unmarshal([]byte(value), result); err != nil {
-				log.Printf("Не удалось десериализовать значение из Redis: %v", err)
-				lastErr = err
-				continue
-			}
-
-			var seqNumber int
-			switch v := result.(type) {
-			case *WalletFullData:
-				seqNumber = v.LastSeqNumber
-			default:
-				if resultMap, ok := result.(map[string]interface{}); ok {
-					if seqVal, exists := resultMap["LastSeqNumber"]; exists {
-						if seqInt, ok := seqVal.(int); ok {
-							seqNumber = seqInt
-						}
-					}
-				}
-			}
-
-			log.Printf("seqNumber: %d, expectedSeq: %d", seqNumber, expectedSeq)
-			if seqNumber == expectedSeq {
-				sCtx.WithAttachments(allure.NewAttachment("Redis Value", allure.JSON, utils.CreatePrettyJSON(result)))
-				return nil
-			}
-
-			log.Printf("Attempt %d: Redis key %s found, but LastSeqNumber %d != %d (expected), retrying in %v...",
-				i+1, key, seqNumber, expectedSeq, delay)
-		} else {
-			lastErr = err
-			log.Printf("Attempt %d: Redis key %s not found or empty, retrying in %v...", i+1, key, delay)
+	err := r.poll(key, r.newWaitOptions(nil), func(value string) (bool, error) {
+		if err := json.Unmarshal([]byte(value), result); err != nil {
+			log.Printf("Не удалось десериализовать значение из Redis: %v", err)
+			return false, err
 		}
 
-		time.Sleep(delay)
-		delay *= 2
+		seqNumber, err := lastSeqNumber(value)
+		if err != nil {
+			log.Printf("Не удалось прочитать LastSeqNumber из значения Redis: %v", err)
+			return false, err
+		}
+
+		log.Printf("seqNumber: %d, expectedSeq: %d", seqNumber, expectedSeq)
+		if seqNumber != expectedSeq {
+			return false, fmt.Errorf("LastSeqNumber %d != %d (expected)", seqNumber, expectedSeq)
+		}
+		return true, nil
+	})
+	if err != nil {
+		log.Printf("Не удалось получить значение из Redis с ожидаемым LastSeqNumber: %v", err)
+		return fmt.Errorf("redis get with sequence check failed: %w", err)
 	}
 
-	log.Printf("Не удалось получить значение из Redis с ожидаемым LastSeqNumber после %d попыток: %v", maxAttempts, lastErr)
-	return fmt.Errorf("redis get with sequence check failed after %d attempts: %w", maxAttempts, lastErr)
+	sCtx.WithAttachments(allure.NewAttachment("Redis Value", allure.JSON, utils.CreatePrettyJSON(result)))
+	return nil
+}
+
+// lastSeqNumber читает LastSeqNumber прямо из JSON, поэтому проверка не зависит от типа result.
+func lastSeqNumber(value string) (int, error) {
+	var seq struct {
+		LastSeqNumber int `json:"LastSeqNumber"`
+	}
+	err := json.Unmarshal([]byte(value), &seq)
+	return seq.LastSeqNumber, err
 }
 
 func (r *RedisClient) Close() error {
+	if r.watcher != nil {
+		r.watcher.Close()
+	}
 	return r.client.Close()
 }
@@ -0,0 +1,51 @@
+package redis
+
+import (
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+)
+
+// Get читает ключ с повторами и декодирует JSON-значение в T.
+func Get[T any](sCtx provider.StepCtx, r *RedisClient, key string) (T, error) {
+	var result T
+	err := r.GetWithRetry(sCtx, key, &result)
+	return result, err
+}
+
+// GetWithSeq ждет, пока LastSeqNumber значения ключа станет равен expectedSeq, и декодирует его в T.
+func GetWithSeq[T any](sCtx provider.StepCtx, r *RedisClient, key string, expectedSeq int) (T, error) {
+	var result T
+	err := r.GetWithSeqCheck(sCtx, key, &result, expectedSeq)
+	return result, err
+}
+
+// Repository дает типизированный доступ к проекциям игрока и кошелька.
+type Repository struct {
+	player *RedisClient
+	wallet *RedisClient
+}
+
+func NewRepository(player, wallet *RedisClient) *Repository {
+	return &Repository{
+		player: player,
+		wallet: wallet,
+	}
+}
+
+// PlayerWallets возвращает кошельки игрока по UUID игрока (Player.ExternalID из Kafka).
+func (r *Repository) PlayerWallets(sCtx provider.StepCtx, playerUUID string) (WalletsMap, error) {
+	return Get[WalletsMap](sCtx, r.player, PlayerWalletsKey(playerUUID))
+}
+
+func (r *Repository) WalletAggregate(sCtx provider.StepCtx, walletUUID string) (WalletFullData, error) {
+	return Get[WalletFullData](sCtx, r.wallet, WalletAggregateKey(walletUUID))
+}
+
+// WaitForSeq возвращает агрегат кошелька после применения события с последовательностью seq.
+func (r *Repository) WaitForSeq(sCtx provider.StepCtx, walletUUID string, seq int) (WalletFullData, error) {
+	return GetWithSeq[WalletFullData](sCtx, r.wallet, WalletAggregateKey(walletUUID), seq)
+}
+
+// WaitForWallet ждет, пока агрегат кошелька не удовлетворит predicate, например нужный баланс или лимит.
+func (r *Repository) WaitForWallet(sCtx provider.StepCtx, walletUUID string, predicate func(WalletFullData) bool, opts ...WaitOption) (WalletFullData, error) {
+	return WaitFor(sCtx, r.wallet, WalletAggregateKey(walletUUID), predicate, opts...)
+}
@@ -0,0 +1,278 @@
+package redis
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"CB_auto/pkg/redact"
+	"CB_auto/pkg/utils"
+
+	"github.com/ozontech/allure-go/pkg/allure"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+)
+
+var ErrConditionNotMet = errors.New("redis: condition not met before deadline")
+
+// defaultMaxRetryDelay ограничивает задержку между попытками, если redis.maxRetryDelay не задан.
+const defaultMaxRetryDelay = 5 * time.Second
+
+type waitOptions struct {
+	deadline    time.Duration
+	attempts    int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+	expected    any
+	hasExpected bool
+}
+
+type WaitOption func(*waitOptions)
+
+// WithDeadline ограничивает ожидание общим временем вместо числа попыток.
+func WithDeadline(d time.Duration) WaitOption {
+	return func(o *waitOptions) {
+		o.deadline = d
+	}
+}
+
+// WithBackoff задает начальную и максимальную задержку между попытками.
+func WithBackoff(base, max time.Duration) WaitOption {
+	return func(o *waitOptions) {
+		o.baseDelay = base
+		o.maxDelay = max
+	}
+}
+
+// WithExpected задает ожидаемое значение (целиком или частично, например map полей),
+// с которым сравнивается последнее прочитанное значение при неудаче ожидания.
+func WithExpected(expected any) WaitOption {
+	return func(o *waitOptions) {
+		o.expected = expected
+		o.hasExpected = true
+	}
+}
+
+func (r *RedisClient) newWaitOptions(opts []WaitOption) waitOptions {
+	o := waitOptions{
+		deadline:  r.waitTimeout,
+		attempts:  r.retryAttempts,
+		baseDelay: r.retryDelay,
+		maxDelay:  r.maxRetryDelay,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.maxDelay <= 0 {
+		o.maxDelay = defaultMaxRetryDelay
+	}
+	if o.baseDelay <= 0 || o.baseDelay > o.maxDelay {
+		o.baseDelay = o.maxDelay
+	}
+	if o.attempts <= 0 {
+		o.attempts = 1
+	}
+	return o
+}
+
+// backoff — экспоненциальная задержка с потолком и случайным разбросом в пределах второй половины интервала,
+// чтобы параллельные тесты не опрашивали Redis синхронно.
+type backoff struct {
+	next time.Duration
+	max  time.Duration
+}
+
+func (b *backoff) Delay() time.Duration {
+	delay := b.next
+	if b.next < b.max {
+		b.next = min(b.next*2, b.max)
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+}
+
+// poll читает ключ, пока check не вернет true, или до исчерпания попыток либо дедлайна.
+// Если включены keyspace-уведомления, следующая попытка начинается сразу после изменения ключа.
+// Возвращает последнюю ошибку чтения или check, а если ее не было — ErrConditionNotMet.
+func (r *RedisClient) poll(key string, o waitOptions, check func(value string) (bool, error)) error {
+	var deadline time.Time
+	if o.deadline > 0 {
+		deadline = time.Now().Add(o.deadline)
+	}
+	b := backoff{next: o.baseDelay, max: o.maxDelay}
+
+	watching := false
+	if r.watcher != nil {
+		release, err := r.watcher.Watch(key)
+		if err != nil {
+			log.Printf("Подписка на изменения ключа %s недоступна, ожидание будет опросом: %v", key, err)
+		} else {
+			watching = true
+			defer release()
+		}
+	}
+
+	var lastErr error
+	for attempt := 1; ; attempt++ {
+		var changed <-chan struct{}
+		if watching {
+			changed = r.watcher.Changed(key)
+		}
+
+		value, err := r.Get(key)
+		switch {
+		case err != nil:
+			lastErr = err
+		case value == "":
+			lastErr = ErrKeyNotFound
+		default:
+			done, err := check(value)
+			if done {
+				return nil
+			}
+			lastErr = err
+		}
+
+		delay := b.Delay()
+		if deadline.IsZero() {
+			if attempt >= o.attempts {
+				break
+			}
+		} else {
+			remaining := time.Until(deadline)
+			if remaining <= 0 {
+				break
+			}
+			delay = min(delay, remaining)
+		}
+
+		log.Printf("Attempt %d: Redis key %s not ready, retrying in %v...", attempt, key, delay)
+		waitForChange(changed, delay)
+	}
+
+	if lastErr == nil {
+		return ErrConditionNotMet
+	}
+	return lastErr
+}
+
+// waitForChange ждет изменения ключа, но не дольше delay. При nil-канале (уведомления выключены) это обычная пауза.
+func waitForChange(changed <-chan struct{}, delay time.Duration) {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-changed:
+	case <-timer.C:
+	}
+}
+
+// WaitFor ждет, пока значение ключа, декодированное в T, не удовлетворит predicate.
+// При неудаче к шагу прикладываются последнее прочитанное значение и его расхождения с WithExpected.
+func WaitFor[T any](sCtx provider.StepCtx, r *RedisClient, key string, predicate func(T) bool, opts ...WaitOption) (T, error) {
+	o := r.newWaitOptions(opts)
+
+	var last T
+	observed := false
+	err := r.poll(key, o, func(value string) (bool, error) {
+		var current T
+		if err := json.Unmarshal([]byte(value), &current); err != nil {
+			log.Printf("Не удалось десериализовать значение из Redis: %v", err)
+			return false, err
+		}
+		last, observed = current, true
+		return predicate(current), nil
+	})
+	if err != nil {
+		log.Printf("Значение ключа %s в Redis не достигло ожидаемого состояния: %v", key, err)
+		if observed {
+			sCtx.WithAttachments(allure.NewAttachment("Redis Last Observed Value", allure.JSON, utils.CreatePrettyJSON(last)))
+			if o.hasExpected {
+				sCtx.WithAttachments(allure.NewAttachment("Redis Wait Diff", allure.Text, []byte(diffJSON(o.expected, last))))
+			}
+		}
+		return last, fmt.Errorf("redis wait for key %s failed: %w", key, err)
+	}
+
+	sCtx.WithAttachments(allure.NewAttachment("Redis Value", allure.JSON, utils.CreatePrettyJSON(last)))
+	return last, nil
+}
+
+// diffJSON сравнивает поля expected с теми же полями actual. Поля, отсутствующие в expected, не учитываются,
+// поэтому expected может быть частичным.
+func diffJSON(expected, actual any) string {
+	want := flattenJSON(expected)
+	got := flattenJSON(actual)
+
+	paths := make([]string, 0, len(want))
+	for path := range want {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var sb strings.Builder
+	for _, path := range paths {
+		actualValue, ok := got[path]
+		if !ok {
+			actualValue = "<отсутствует>"
+		}
+		if actualValue == want[path] {
+			continue
+		}
+		fmt.Fprintf(&sb, "%s:\n  ожидалось: %s\n  получено:  %s\n", path, want[path], actualValue)
+	}
+	if sb.Len() == 0 {
+		return "Указанные поля совпадают, условие ожидания не выполнено по другим полям"
+	}
+	return sb.String()
+}
+
+// flattenJSON раскладывает значение в пары "путь -> JSON-значение листа" после маскирования чувствительных полей.
+func flattenJSON(v any) map[string]string {
+	result := make(map[string]string)
+	data, err := json.Marshal(v)
+	if err != nil {
+		result[""] = fmt.Sprintf("<ошибка сериализации: %v>", err)
+		return result
+	}
+	var doc any
+	if err := json.Unmarshal(redact.JSON(data), &doc); err != nil {
+		result[""] = string(data)
+		return result
+	}
+	flattenInto(result, "", doc)
+	return result
+}
+
+func flattenInto(result map[string]string, prefix string, node any) {
+	join := func(key string) string {
+		if prefix == "" {
+			return key
+		}
+		return prefix + "." + key
+	}
+
+	switch value := node.(type) {
+	case map[string]any:
+		if len(value) == 0 {
+			result[prefix] = "{}"
+		}
+		for key, child := range value {
+			flattenInto(result, join(key), child)
+		}
+	case []any:
+		if len(value) == 0 {
+			result[prefix] = "[]"
+		}
+		for i, child := range value {
+			flattenInto(result, fmt.Sprintf("%s[%d]", prefix, i), child)
+		}
+	default:
+		data, _ := json.Marshal(value)
+		result[prefix] = string(data)
+	}
+}
@@ -0,0 +1,317 @@
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+const Mask = "***"
+
+// Rules задает, что скрывать в логах и allure-аттачментах.
+// JSONFields из одного сегмента ("password") скрывают поле на любой глубине,
+// пути из нескольких сегментов ("player.phone", "*.amount") считаются от корня документа,
+// массивы при этом проходятся прозрачно.
+type Rules struct {
+	Headers    []string `json:"headers"`
+	JSONFields []string `json:"json_fields"`
+	Patterns   []string `json:"patterns"`
+}
+
+var DefaultRules = Rules{
+	Headers:    []string{"Authorization", "Cookie", "Set-Cookie"},
+	JSONFields: []string{"password", "token", "refreshToken"},
+}
+
+type Redactor struct {
+	headers  map[string]bool
+	fields   map[string]bool
+	paths    [][]string
+	patterns []*regexp.Regexp
+}
+
+func New(rules Rules) (*Redactor, error) {
+	r := &Redactor{
+		headers: make(map[string]bool),
+		fields:  make(map[string]bool),
+	}
+	for _, header := range rules.Headers {
+		r.headers[http.CanonicalHeaderKey(header)] = true
+	}
+	for _, field := range rules.JSONFields {
+		segments := strings.Split(field, ".")
+		if len(segments) == 1 {
+			r.fields[strings.ToLower(field)] = true
+			continue
+		}
+		r.paths = append(r.paths, segments)
+	}
+	for _, pattern := range rules.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %v", pattern, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	return r, nil
+}
+
+var (
+	mu       sync.RWMutex
+	instance = mustNew(DefaultRules)
+)
+
+func mustNew(rules Rules) *Redactor {
+	r, err := New(rules)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// Configure дополняет DefaultRules правилами из конфигурации и делает результат редактором по умолчанию.
+func Configure(rules Rules) error {
+	merged := Rules{
+		Headers:    append(append([]string{}, DefaultRules.Headers...), rules.Headers...),
+		JSONFields: append(append([]string{}, DefaultRules.JSONFields...), rules.JSONFields...),
+		Patterns:   append(append([]string{}, DefaultRules.Patterns...), rules.Patterns...),
+	}
+	r, err := New(merged)
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	instance = r
+	mu.Unlock()
+	return nil
+}
+
+func Default() *Redactor {
+	mu.RLock()
+	defer mu.RUnlock()
+	return instance
+}
+
+func Headers(header http.Header) http.Header {
+	return Default().Headers(header)
+}
+
+func JSON(data []byte) []byte {
+	return Default().JSON(data)
+}
+
+func Text(text string) string {
+	return Default().Text(text)
+}
+
+// Headers возвращает копию заголовков со скрытыми значениями.
+func (r *Redactor) Headers(header http.Header) http.Header {
+	if len(header) == 0 {
+		return header
+	}
+	redacted := make(http.Header, len(header))
+	for name, values := range header {
+		if r.IsSecretHeader(name) {
+			redacted[name] = []string{Mask}
+			continue
+		}
+		copied := make([]string, len(values))
+		for i, value := range values {
+			copied[i] = r.Text(value)
+		}
+		redacted[name] = copied
+	}
+	return redacted
+}
+
+func (r *Redactor) IsSecretHeader(name string) bool {
+	return r.headers[http.CanonicalHeaderKey(name)]
+}
+
+// JSON скрывает поля и совпадения с шаблонами в JSON-документе.
+// Документ переписывается по токенам: порядок ключей и исходная запись нетронутых значений сохраняются,
+// чтобы аттачменты разных запусков можно было сравнивать. Данные, которые не являются JSON, обрабатываются как текст.
+func (r *Redactor) JSON(data []byte) []byte {
+	if len(data) == 0 {
+		return data
+	}
+
+	w := &jsonRewriter{redactor: r, data: data, decoder: json.NewDecoder(bytes.NewReader(data))}
+	w.decoder.UseNumber()
+	tok, raw, err := w.next()
+	if err == nil {
+		err = w.value(tok, raw, nil)
+	}
+	if err != nil {
+		return []byte(r.Text(string(data)))
+	}
+	return w.out.Bytes()
+}
+
+// jsonRewriter копирует токены документа в out, заменяя секретные поля и строки с совпадениями шаблонов.
+type jsonRewriter struct {
+	redactor *Redactor
+	data     []byte
+	decoder  *json.Decoder
+	offset   int64
+	out      bytes.Buffer
+}
+
+// next возвращает следующий токен и его исходную запись без разделителей.
+func (w *jsonRewriter) next() (json.Token, []byte, error) {
+	tok, err := w.decoder.Token()
+	if err != nil {
+		return nil, nil, err
+	}
+	end := w.decoder.InputOffset()
+	raw := bytes.TrimLeft(w.data[w.offset:end], " \t\r\n,:")
+	w.offset = end
+	return tok, raw, nil
+}
+
+func (w *jsonRewriter) value(tok json.Token, raw []byte, path []string) error {
+	switch v := tok.(type) {
+	case json.Delim:
+		if v == '{' {
+			return w.object(path)
+		}
+		return w.array(path)
+	case string:
+		if text := w.redactor.Text(v); text != v {
+			return w.writeString(text)
+		}
+	}
+	w.out.Write(raw)
+	return nil
+}
+
+func (w *jsonRewriter) object(path []string) error {
+	w.out.WriteByte('{')
+	for first := true; w.decoder.More(); first = false {
+		keyTok, keyRaw, err := w.next()
+		if err != nil {
+			return err
+		}
+		key, _ := keyTok.(string)
+		if !first {
+			w.out.WriteByte(',')
+		}
+		w.out.Write(keyRaw)
+		w.out.WriteByte(':')
+
+		tok, raw, err := w.next()
+		if err != nil {
+			return err
+		}
+		childPath := append(path[:len(path):len(path)], key)
+		if w.redactor.isSecretField(key, childPath) {
+			if err := w.skip(tok); err != nil {
+				return err
+			}
+			if err := w.writeString(Mask); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := w.value(tok, raw, childPath); err != nil {
+			return err
+		}
+	}
+	if _, _, err := w.next(); err != nil {
+		return err
+	}
+	w.out.WriteByte('}')
+	return nil
+}
+
+// array проходит элементы с путем самого массива: пути в правилах не содержат индексов.
+func (w *jsonRewriter) array(path []string) error {
+	w.out.WriteByte('[')
+	for first := true; w.decoder.More(); first = false {
+		tok, raw, err := w.next()
+		if err != nil {
+			return err
+		}
+		if !first {
+			w.out.WriteByte(',')
+		}
+		if err := w.value(tok, raw, path); err != nil {
+			return err
+		}
+	}
+	if _, _, err := w.next(); err != nil {
+		return err
+	}
+	w.out.WriteByte(']')
+	return nil
+}
+
+// skip пропускает значение, начавшееся с tok, вместе со всеми вложенными токенами.
+func (w *jsonRewriter) skip(tok json.Token) error {
+	depth := 0
+	for {
+		if delim, ok := tok.(json.Delim); ok {
+			if delim == '{' || delim == '[' {
+				depth++
+			} else {
+				depth--
+			}
+		}
+		if depth == 0 {
+			return nil
+		}
+		var err error
+		if tok, _, err = w.next(); err != nil {
+			return err
+		}
+	}
+}
+
+// writeString записывает строку без HTML-экранирования, как она выглядела бы в исходном теле.
+func (w *jsonRewriter) writeString(s string) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(s); err != nil {
+		return err
+	}
+	w.out.Write(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+	return nil
+}
+
+// Text применяет шаблоны Patterns к произвольной строке.
+func (r *Redactor) Text(text string) string {
+	for _, re := range r.patterns {
+		text = re.ReplaceAllString(text, Mask)
+	}
+	return text
+}
+
+func (r *Redactor) isSecretField(key string, path []string) bool {
+	if r.fields[strings.ToLower(key)] {
+		return true
+	}
+	for _, secretPath := range r.paths {
+		if matchPath(secretPath, path) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchPath(pattern, path []string) bool {
+	if len(pattern) != len(path) {
+		return false
+	}
+	for i := range pattern {
+		if pattern[i] != "*" && !strings.EqualFold(pattern[i], path[i]) {
+			return false
+		}
+	}
+	return true
+}
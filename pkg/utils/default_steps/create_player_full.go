@@ -8,6 +8,7 @@ import (
 	publicModels "CB_auto/internal/client/public/models"
 	clientTypes "CB_auto/internal/client/types"
 	"CB_auto/internal/config"
+	"CB_auto/internal/repository/player"
 	"CB_auto/internal/transport/kafka"
 	"CB_auto/internal/transport/nats"
 	"CB_auto/internal/transport/redis"
@@ -16,6 +17,28 @@ import (
 	"github.com/ozontech/allure-go/pkg/framework/provider"
 )
 
+// PlayerOption включает дополнительные проверки в шагах создания игрока.
+type PlayerOption func(*playerOptions)
+
+type playerOptions struct {
+	playerRepo *player.Repository
+}
+
+// WithPlayerRepo добавляет проверку сохранения игрока в core-БД. Без него шаг пропускается.
+func WithPlayerRepo(playerRepo *player.Repository) PlayerOption {
+	return func(o *playerOptions) {
+		o.playerRepo = playerRepo
+	}
+}
+
+func newPlayerOptions(opts []PlayerOption) playerOptions {
+	var o playerOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
 // CreatePlayerWithFullRegistration создаёт игрока через полную регистрацию с подтверждением телефона
 func CreatePlayerWithFullRegistration(
 	sCtx provider.StepCtx,
@@ -24,6 +47,7 @@ func CreatePlayerWithFullRegistration(
 	config *config.Config,
 	redisPlayerClient *redis.RedisClient,
 	redisWalletClient *redis.RedisClient,
+	opts ...PlayerOption,
 ) PlayerData {
 	// Внутренняя структура для хранения данных при создании игрока
 	var registrationData struct {
@@ -38,7 +62,9 @@ func CreatePlayerWithFullRegistration(
 		wallets                  redis.WalletsMap
 	}
 
+	o := newPlayerOptions(opts)
 	playerData := PlayerData{}
+	redisRepo := redis.NewRepository(redisPlayerClient, redisWalletClient)
 
 	// Шаг 1: Запрос подтверждения телефона
 	sCtx.WithNewStep("Запрос подтверждения телефона", func(sCtx provider.StepCtx) {
@@ -61,7 +87,7 @@ func CreatePlayerWithFullRegistration(
 		registrationData.phoneConfirmationMessage = kafka.FindMessageByFilter(sCtx, kafkaClient, func(msg kafka.PlayerMessage) bool {
 			return msg.Message.EventType == kafka.PlayerEventConfirmationPhone &&
 				msg.Player.Phone == phoneNumberWithoutPlus
-		})
+		}, kafka.ByField("player.phone", phoneNumberWithoutPlus))
 
 		sCtx.Require().Equal(string(kafka.PlayerEventConfirmationPhone), string(registrationData.phoneConfirmationMessage.Message.EventType),
 			"Kafka: Сообщение player.confirmationPhone найдено")
@@ -130,11 +156,30 @@ func CreatePlayerWithFullRegistration(
 		registrationData.registrationMessage = kafka.FindMessageByFilter(sCtx, kafkaClient, func(msg kafka.PlayerMessage) bool {
 			return msg.Message.EventType == kafka.PlayerEventSignUpFull &&
 				msg.Player.Phone == phoneNumber
-		})
+		}, kafka.ByField("player.phone", phoneNumber))
 
 		sCtx.Require().NotEmpty(registrationData.registrationMessage.Player.ExternalID, "ExternalID игрока не пустой")
 	})
 
+	// Проверка сохранения игрока в core-БД
+	if o.playerRepo != nil {
+		sCtx.WithNewStep("Проверка игрока в core-БД", func(sCtx provider.StepCtx) {
+			playerUUID := registrationData.registrationMessage.Player.ExternalID
+			phoneNumber := strings.TrimPrefix(registrationData.phoneVerificationRequest.Body.Contact, "+")
+
+			playerFromDB, err := o.playerRepo.GetPlayerByUUID(sCtx, playerUUID)
+			sCtx.Require().NoError(err, "DB: Игрок получен из core-БД")
+			sCtx.Require().NotNil(playerFromDB, "DB: Игрок найден в core-БД")
+			sCtx.Assert().Equal(phoneNumber, playerFromDB.Phone.String, "DB: Телефон игрока соответствует регистрации")
+			sCtx.Assert().Equal(config.Node.DefaultCurrency, playerFromDB.Currency, "DB: Валюта игрока соответствует регистрации")
+			sCtx.Assert().Equal(config.Node.DefaultCountry, playerFromDB.Country, "DB: Страна игрока соответствует регистрации")
+
+			confirmed, err := o.playerRepo.IsContactConfirmed(sCtx, playerUUID, player.ContactTypePhone, phoneNumber)
+			sCtx.Require().NoError(err, "DB: Состояние подтверждения телефона получено")
+			sCtx.Assert().True(confirmed, "DB: Телефон игрока подтвержден")
+		})
+	}
+
 	// Шаг 6: Авторизация
 	sCtx.WithNewStep("Авторизация", func(sCtx provider.StepCtx) {
 		req := &clientTypes.Request[publicModels.TokenCheckRequestBody]{
@@ -150,8 +195,7 @@ func CreatePlayerWithFullRegistration(
 
 	// Шаг 7: Получение WalletUUID из Redis
 	sCtx.WithNewStep("Получение WalletUUID из Redis", func(sCtx provider.StepCtx) {
-		var wallets redis.WalletsMap
-		err := redisPlayerClient.GetWithRetry(sCtx, registrationData.registrationMessage.Player.ExternalID, &wallets)
+		wallets, err := redisRepo.PlayerWallets(sCtx, registrationData.registrationMessage.Player.ExternalID)
 
 		registrationData.wallets = wallets
 		sCtx.Require().NoError(err, "Значение кошелька получено из Redis")
@@ -171,15 +215,12 @@ func CreatePlayerWithFullRegistration(
 			break
 		}
 
-		var updatedWalletData redis.WalletFullData
-		err := redisWalletClient.GetWithRetry(
-			sCtx,
-			walletUUID,
-			&updatedWalletData)
+		updatedWalletData, err := redisRepo.WalletAggregate(sCtx, walletUUID)
 		sCtx.Require().NoError(err, "Получены обновленные данные кошелька из Redis")
 
 		playerData = PlayerData{
 			Auth:       registrationData.authorizationResponse,
+			Session:    publicAPI.NewPlayerSession(sCtx, publicClient, registrationData.authorizationResponse.Body),
 			WalletData: updatedWalletData,
 		}
 	})
@@ -12,6 +12,7 @@ import (
 	publicModels "CB_auto/internal/client/public/models"
 	clientTypes "CB_auto/internal/client/types"
 	"CB_auto/internal/config"
+	"CB_auto/internal/repository/player"
 	"CB_auto/internal/transport/kafka"
 	"CB_auto/internal/transport/nats"
 	"CB_auto/internal/transport/redis"
@@ -22,6 +23,7 @@ import (
 
 type PlayerData struct {
 	Auth       *clientTypes.Response[publicModels.TokenCheckResponseBody]
+	Session    *publicAPI.PlayerSession
 	WalletData redis.WalletFullData
 }
 
@@ -35,6 +37,7 @@ func CreateVerifiedPlayer(
 	redisWalletClient *redis.RedisClient,
 	natsClient *nats.NatsClient,
 	depositAmount float64,
+	opts ...PlayerOption,
 ) PlayerData {
 	// Внутренняя структура для хранения оперативных данных при создании игрока
 	var registrationData struct {
@@ -53,7 +56,9 @@ func CreateVerifiedPlayer(
 		depositeEvent            *nats.NatsMessage[nats.DepositedMoneyPayload]
 	}
 
+	o := newPlayerOptions(opts)
 	playerData := PlayerData{}
+	redisRepo := redis.NewRepository(redisPlayerClient, redisWalletClient)
 
 	// Шаг 1: Запрос подтверждения телефона
 	sCtx.WithNewStep("Запрос подтверждения телефона", func(sCtx provider.StepCtx) {
@@ -76,7 +81,7 @@ func CreateVerifiedPlayer(
 		registrationData.phoneConfirmationMessage = kafka.FindMessageByFilter(sCtx, kafkaClient, func(msg kafka.PlayerMessage) bool {
 			return msg.Message.EventType == kafka.PlayerEventConfirmationPhone &&
 				msg.Player.Phone == phoneNumberWithoutPlus
-		})
+		}, kafka.ByField("player.phone", phoneNumberWithoutPlus))
 
 		sCtx.Require().Equal(string(kafka.PlayerEventConfirmationPhone), string(registrationData.phoneConfirmationMessage.Message.EventType),
 			"Kafka: Сообщение player.confirmationPhone найдено")
@@ -145,7 +150,7 @@ func CreateVerifiedPlayer(
 		registrationData.registrationMessage = kafka.FindMessageByFilter(sCtx, kafkaClient, func(msg kafka.PlayerMessage) bool {
 			return msg.Message.EventType == kafka.PlayerEventSignUpFull &&
 				msg.Player.Phone == phoneNumber
-		})
+		}, kafka.ByField("player.phone", phoneNumber))
 
 		sCtx.Require().NotEmpty(registrationData.registrationMessage.Player.ExternalID, "ExternalID игрока не пустой")
 	})
@@ -235,7 +240,7 @@ func CreateVerifiedPlayer(
 		registrationData.emailConfirmationMessage = kafka.FindMessageByFilter(sCtx, kafkaClient, func(msg kafka.PlayerMessage) bool {
 			return msg.Message.EventType == kafka.PlayerEventConfirmationEmail &&
 				msg.Player.Email == registrationData.emailVerificationRequest.Body.Contact
-		})
+		}, kafka.ByField("player.email", registrationData.emailVerificationRequest.Body.Contact))
 
 	})
 
@@ -257,6 +262,32 @@ func CreateVerifiedPlayer(
 		sCtx.Require().Equal(http.StatusCreated, resp.StatusCode, "Подтверждение email")
 	})
 
+	// Проверка верификации и контактов игрока в core-БД
+	if o.playerRepo != nil {
+		sCtx.WithNewStep("Проверка верификации и контактов игрока в core-БД", func(sCtx provider.StepCtx) {
+			playerUUID := registrationData.registrationMessage.Player.ExternalID
+
+			playerFromDB, err := o.playerRepo.GetPlayerByUUID(sCtx, playerUUID)
+			sCtx.Require().NoError(err, "DB: Игрок получен из core-БД")
+			sCtx.Require().NotNil(playerFromDB, "DB: Игрок найден в core-БД")
+
+			verification, err := o.playerRepo.GetVerification(sCtx, registrationData.verifications.Body[0].DocumentID,
+				int(capModels.VerificationStatusApproved))
+			sCtx.Require().NoError(err, "DB: Верификация документа одобрена")
+			sCtx.Assert().Equal(playerUUID, verification.PlayerUUID, "DB: Верификация принадлежит игроку")
+
+			phoneConfirmed, err := o.playerRepo.IsContactConfirmed(sCtx, playerUUID, player.ContactTypePhone,
+				strings.TrimPrefix(registrationData.phoneVerificationRequest.Body.Contact, "+"))
+			sCtx.Require().NoError(err, "DB: Состояние подтверждения телефона получено")
+			sCtx.Assert().True(phoneConfirmed, "DB: Телефон игрока подтвержден")
+
+			emailConfirmed, err := o.playerRepo.IsContactConfirmed(sCtx, playerUUID, player.ContactTypeEmail,
+				registrationData.emailVerificationRequest.Body.Contact)
+			sCtx.Require().NoError(err, "DB: Состояние подтверждения email получено")
+			sCtx.Assert().True(emailConfirmed, "DB: Email игрока подтвержден")
+		})
+	}
+
 	// Шаг 14: Установка лимита на одиночную ставку
 	sCtx.WithNewStep("Установка лимита на одиночную ставку", func(sCtx provider.StepCtx) {
 		req := &clientTypes.Request[publicModels.SetSingleBetLimitRequestBody]{
@@ -293,8 +324,7 @@ func CreateVerifiedPlayer(
 
 	// Шаг 16: Получение WalletUUID из Redis
 	sCtx.WithNewStep("Получение WalletUUID из Redis", func(sCtx provider.StepCtx) {
-		var wallets redis.WalletsMap
-		err := redisPlayerClient.GetWithRetry(sCtx, registrationData.registrationMessage.Player.ExternalID, &wallets)
+		wallets, err := redisRepo.PlayerWallets(sCtx, registrationData.registrationMessage.Player.ExternalID)
 
 		registrationData.wallets = wallets
 		sCtx.Require().NoError(err, "Значение кошелька получено из Redis")
@@ -351,16 +381,12 @@ func CreateVerifiedPlayer(
 				break
 			}
 
-			var updatedWalletData redis.WalletFullData
-			err := redisWalletClient.GetWithSeqCheck(
-				sCtx,
-				walletUUID,
-				&updatedWalletData,
-				int(registrationData.depositEvent.Sequence))
+			updatedWalletData, err := redisRepo.WaitForSeq(sCtx, walletUUID, int(registrationData.depositEvent.Sequence))
 			sCtx.Require().NoError(err, "Получены обновленные данные кошелька из Redis")
 
 			playerData = PlayerData{
 				Auth:       registrationData.authorizationResponse,
+				Session:    publicAPI.NewPlayerSession(sCtx, publicClient, registrationData.authorizationResponse.Body),
 				WalletData: updatedWalletData,
 			}
 		})
@@ -373,16 +399,12 @@ func CreateVerifiedPlayer(
 				break
 			}
 
-			var updatedWalletData redis.WalletFullData
-			err := redisWalletClient.GetWithRetry(
-				sCtx,
-				walletUUID,
-				&updatedWalletData,
-			)
+			updatedWalletData, err := redisRepo.WalletAggregate(sCtx, walletUUID)
 			sCtx.Require().NoError(err, "Получены обновленные данные кошелька из Redis")
 
 			playerData = PlayerData{
 				Auth:       registrationData.authorizationResponse,
+				Session:    publicAPI.NewPlayerSession(sCtx, publicClient, registrationData.authorizationResponse.Body),
 				WalletData: updatedWalletData,
 			}
 		})
@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type jwtClaims struct {
+	ExpiresAt int64 `json:"exp"`
+}
+
+// ParseJWTExpiration возвращает время истечения токена из claim exp без проверки подписи.
+func ParseJWTExpiration(token string) (time.Time, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, fmt.Errorf("invalid token format")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to decode payload: %v", err)
+	}
+
+	var claims jwtClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse claims: %v", err)
+	}
+
+	return time.Unix(claims.ExpiresAt, 0), nil
+}
@@ -2,9 +2,12 @@ package utils
 
 import (
 	clientTypes "CB_auto/internal/client/types"
+	"CB_auto/pkg/redact"
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"log"
+	"net/http"
 	"strings"
 	"time"
 
@@ -30,15 +33,18 @@ func CreateHttpAttachRequest[T any](req *clientTypes.Request[T]) []byte {
 	if len(req.Headers) > 0 {
 		sb.WriteString("Headers:\n")
 		for k, v := range req.Headers {
+			if redact.Default().IsSecretHeader(k) {
+				v = redact.Mask
+			}
 			sb.WriteString(fmt.Sprintf("  %s: %s\n", k, v))
 		}
 	}
 	if req.Body != nil {
-		b, err := json.MarshalIndent(req.Body, "", "  ")
+		b, err := json.Marshal(req.Body)
 		if err != nil {
-			sb.WriteString(fmt.Sprintf("Body: %+v\n", req.Body))
+			sb.WriteString("Body: " + redact.Text(fmt.Sprintf("%+v", req.Body)) + "\n")
 		} else {
-			sb.WriteString("Body: " + string(b) + "\n")
+			writeHttpAttachBody(&sb, b)
 		}
 	}
 	return []byte(sb.String())
@@ -47,12 +53,7 @@ func CreateHttpAttachRequest[T any](req *clientTypes.Request[T]) []byte {
 func CreateHttpAttachResponse[V any](resp *clientTypes.Response[V]) []byte {
 	var sb strings.Builder
 	sb.WriteString(fmt.Sprintf("StatusCode: %d\n", resp.StatusCode))
-	if len(resp.Headers) > 0 {
-		sb.WriteString("Headers:\n")
-		for k, v := range resp.Headers {
-			sb.WriteString(fmt.Sprintf("  %s: %s\n", k, strings.Join(v, ", ")))
-		}
-	}
+	writeHttpAttachHeaders(&sb, resp.Headers)
 	if resp.Error != nil {
 		sb.WriteString("Error:\n")
 		sb.WriteString(fmt.Sprintf("  StatusCode: %d\n", resp.Error.StatusCode))
@@ -65,24 +66,30 @@ func CreateHttpAttachResponse[V any](resp *clientTypes.Response[V]) []byte {
 				sb.WriteString(fmt.Sprintf("    %s: %s\n", field, strings.Join(errMsgs, ", ")))
 			}
 		}
-		sb.WriteString(fmt.Sprintf("  Body: %s\n", resp.Error.Body))
+		sb.WriteString(fmt.Sprintf("  Body: %s\n", redact.JSON([]byte(resp.Error.Body))))
 	} else {
-		b, err := json.MarshalIndent(resp.Body, "", "  ")
+		b, err := json.Marshal(resp.Body)
 		if err != nil {
-			sb.WriteString(fmt.Sprintf("Body: %+v\n", resp.Body))
+			sb.WriteString("Body: " + redact.Text(fmt.Sprintf("%+v", resp.Body)) + "\n")
 		} else {
-			sb.WriteString("Body: " + string(b) + "\n")
+			writeHttpAttachBody(&sb, b)
 		}
 	}
 	return []byte(sb.String())
 }
 
+// CreatePrettyJSON форматирует значение для allure-аттачмента, скрывая секреты по правилам redact.
 func CreatePrettyJSON[T any](v T) []byte {
-	prettyJSON, err := json.MarshalIndent(v, "", "    ")
+	data, err := json.Marshal(v)
 	if err != nil {
 		return []byte(fmt.Sprintf("Ошибка при форматировании JSON: %v", err))
 	}
-	return prettyJSON
+
+	var prettyJSON bytes.Buffer
+	if err := json.Indent(&prettyJSON, redact.JSON(data), "", "    "); err != nil {
+		return []byte(fmt.Sprintf("Ошибка при форматировании JSON: %v", err))
+	}
+	return prettyJSON.Bytes()
 }
 
 func IsTimeInRange(timestamp int64, rangeInSeconds int64) bool {
@@ -101,3 +108,43 @@ func RequireNoError(t provider.T, err error, format string, args ...interface{})
 		t.Fatalf(format+": %v", append(args, err)...)
 	}
 }
+
+func CreateHttpAttachRawRequest(req *http.Request, header http.Header, body []byte) []byte {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Method: %s\n", req.Method))
+	sb.WriteString(fmt.Sprintf("URL: %s\n", req.URL.String()))
+	writeHttpAttachHeaders(&sb, header)
+	writeHttpAttachBody(&sb, body)
+	return []byte(sb.String())
+}
+
+func CreateHttpAttachRawResponse(resp *http.Response, header http.Header, body []byte) []byte {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("StatusCode: %d\n", resp.StatusCode))
+	writeHttpAttachHeaders(&sb, header)
+	writeHttpAttachBody(&sb, body)
+	return []byte(sb.String())
+}
+
+func writeHttpAttachHeaders(sb *strings.Builder, header http.Header) {
+	if len(header) == 0 {
+		return
+	}
+	sb.WriteString("Headers:\n")
+	for k, v := range redact.Headers(header) {
+		sb.WriteString(fmt.Sprintf("  %s: %s\n", k, strings.Join(v, ", ")))
+	}
+}
+
+func writeHttpAttachBody(sb *strings.Builder, body []byte) {
+	if len(body) == 0 {
+		return
+	}
+	body = redact.JSON(body)
+	var pretty bytes.Buffer
+	if err := json.Indent(&pretty, body, "", "  "); err != nil {
+		sb.WriteString("Body: " + string(body) + "\n")
+		return
+	}
+	sb.WriteString("Body: " + pretty.String() + "\n")
+}
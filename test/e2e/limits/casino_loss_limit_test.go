@@ -7,6 +7,7 @@ import (
 	"time"
 
 	capModels "CB_auto/internal/client/cap/models"
+	publicAPI "CB_auto/internal/client/public"
 	publicModels "CB_auto/internal/client/public/models"
 	clientTypes "CB_auto/internal/client/types"
 	"CB_auto/internal/transport/kafka"
@@ -35,7 +36,7 @@ func (s *CasinoLossLimitSuite) TestCasinoLossLimitCreate(t provider.T) {
 	t.Tags("wallet", "limits")
 
 	var testData struct {
-		authToken               string
+		session                 *publicAPI.PlayerSession
 		walletAggregate         redis.WalletFullData
 		casinoLossLimitResponse *clientTypes.Response[publicModels.GetCasinoLossLimitsResponseBody]
 		casinoLossLimitRequest  *clientTypes.Request[publicModels.SetCasinoLossLimitRequestBody]
@@ -57,15 +58,12 @@ func (s *CasinoLossLimitSuite) TestCasinoLossLimitCreate(t provider.T) {
 		sCtx.Require().NotEmpty(playerData.WalletData.WalletUUID, "UUID кошелька получен")
 		sCtx.Require().NotEmpty(playerData.WalletData.PlayerUUID, "UUID игрока получен")
 
-		testData.authToken = playerData.Auth.Body.Token
+		testData.session = playerData.Session
 		testData.walletAggregate = playerData.WalletData
 	})
 
 	t.WithNewStep("Установка лимита на проигрыш", func(sCtx provider.StepCtx) {
 		testData.casinoLossLimitRequest = &clientTypes.Request[publicModels.SetCasinoLossLimitRequestBody]{
-			Headers: map[string]string{
-				"Authorization": fmt.Sprintf("Bearer %s", testData.authToken),
-			},
 			Body: &publicModels.SetCasinoLossLimitRequestBody{
 				Amount:    "100",
 				Currency:  s.Shared.Config.Node.DefaultCurrency,
@@ -74,7 +72,7 @@ func (s *CasinoLossLimitSuite) TestCasinoLossLimitCreate(t provider.T) {
 			},
 		}
 
-		resp := s.Shared.PublicClient.SetCasinoLossLimit(sCtx, testData.casinoLossLimitRequest)
+		resp := testData.session.SetCasinoLossLimit(sCtx, testData.casinoLossLimitRequest)
 		sCtx.Require().Equal(http.StatusCreated, resp.StatusCode, "Лимит на проигрыш установлен")
 	})
 
@@ -86,7 +84,7 @@ func (s *CasinoLossLimitSuite) TestCasinoLossLimitCreate(t provider.T) {
 				msg.PlayerID == testData.walletAggregate.PlayerUUID &&
 				msg.Amount == testData.casinoLossLimitRequest.Body.Amount &&
 				msg.CurrencyCode == testData.casinoLossLimitRequest.Body.Currency
-		})
+		}, kafka.ByField("playerId", testData.walletAggregate.PlayerUUID))
 		sCtx.Require().NotEmpty(testData.limitMessage.ID, "Сообщение о создании лимита на проигрыш из топика limits.v2 получено")
 	})
 
@@ -114,13 +112,9 @@ func (s *CasinoLossLimitSuite) TestCasinoLossLimitCreate(t provider.T) {
 	})
 
 	t.WithNewAsyncStep("Получение созданного лимита через Public API", func(sCtx provider.StepCtx) {
-		req := &clientTypes.Request[any]{
-			Headers: map[string]string{
-				"Authorization": fmt.Sprintf("Bearer %s", testData.authToken),
-			},
-		}
+		req := &clientTypes.Request[any]{}
 
-		testData.casinoLossLimitResponse = s.Shared.PublicClient.GetCasinoLossLimits(sCtx, req)
+		testData.casinoLossLimitResponse = testData.session.GetCasinoLossLimits(sCtx, req)
 		sCtx.Assert().Equal(http.StatusOK, testData.casinoLossLimitResponse.StatusCode, "Public API: Статус-код 200")
 
 		limit := testData.casinoLossLimitResponse.Body[0]
@@ -187,7 +181,7 @@ func (s *CasinoLossLimitSuite) TestCasinoLossLimitCreate(t provider.T) {
 			return msg.Type == kafka.ProjectionEventLimitChanged &&
 				msg.PlayerUUID == testData.walletAggregate.PlayerUUID &&
 				msg.WalletUUID == testData.walletAggregate.WalletUUID
-		})
+		}, kafka.ByField("player_uuid", testData.walletAggregate.PlayerUUID))
 		sCtx.Assert().NotEmpty(projectionMessage.Type, "Kafka: Сообщение projectionSource найдено")
 		sCtx.Assert().Equal(string(kafka.ProjectionEventLimitChanged), string(projectionMessage.Type), "Kafka: Проверка параметра type")
 		sCtx.Assert().Equal(testData.casinoLossEvent.Sequence, projectionMessage.SeqNumber, "Kafka: Проверка параметра seq_number")
@@ -222,7 +216,7 @@ func (s *CasinoLossLimitSuite) TestCasinoLossLimitUpdate(t provider.T) {
 	t.Tags("wallet", "limits", "update")
 
 	var testData struct {
-		authToken                      string
+		session                        *publicAPI.PlayerSession
 		walletAggregate                redis.WalletFullData
 		casinoLossLimitResponse        *clientTypes.Response[publicModels.GetCasinoLossLimitsResponseBody]
 		casinoLossLimitRequest         *clientTypes.Request[publicModels.SetCasinoLossLimitRequestBody]
@@ -247,15 +241,12 @@ func (s *CasinoLossLimitSuite) TestCasinoLossLimitUpdate(t provider.T) {
 		sCtx.Require().NotEmpty(playerData.WalletData.WalletUUID, "UUID кошелька получен")
 		sCtx.Require().NotEmpty(playerData.WalletData.PlayerUUID, "UUID игрока получен")
 
-		testData.authToken = playerData.Auth.Body.Token
+		testData.session = playerData.Session
 		testData.walletAggregate = playerData.WalletData
 	})
 
 	t.WithNewStep("Установка лимита на проигрыш", func(sCtx provider.StepCtx) {
 		testData.casinoLossLimitRequest = &clientTypes.Request[publicModels.SetCasinoLossLimitRequestBody]{
-			Headers: map[string]string{
-				"Authorization": fmt.Sprintf("Bearer %s", testData.authToken),
-			},
 			Body: &publicModels.SetCasinoLossLimitRequestBody{
 				Amount:    "100",
 				Currency:  s.Shared.Config.Node.DefaultCurrency,
@@ -264,7 +255,7 @@ func (s *CasinoLossLimitSuite) TestCasinoLossLimitUpdate(t provider.T) {
 			},
 		}
 
-		resp := s.Shared.PublicClient.SetCasinoLossLimit(sCtx, testData.casinoLossLimitRequest)
+		resp := testData.session.SetCasinoLossLimit(sCtx, testData.casinoLossLimitRequest)
 		sCtx.Require().Equal(http.StatusCreated, resp.StatusCode, "Лимит на проигрыш установлен")
 	})
 
@@ -276,7 +267,7 @@ func (s *CasinoLossLimitSuite) TestCasinoLossLimitUpdate(t provider.T) {
 				msg.PlayerID == testData.walletAggregate.PlayerUUID &&
 				msg.Amount == testData.casinoLossLimitRequest.Body.Amount &&
 				msg.CurrencyCode == testData.casinoLossLimitRequest.Body.Currency
-		})
+		}, kafka.ByField("playerId", testData.walletAggregate.PlayerUUID))
 		sCtx.Require().NotEmpty(testData.createLimitMessage.ID, "Сообщение о создании лимита на проигрыш из топика limits.v2 получено")
 	})
 
@@ -285,9 +276,6 @@ func (s *CasinoLossLimitSuite) TestCasinoLossLimitUpdate(t provider.T) {
 		newAmount := strconv.Itoa(currentAmountInt - 1)
 
 		testData.updateRecalculatedLimitRequest = &clientTypes.Request[publicModels.UpdateRecalculatedLimitRequestBody]{
-			Headers: map[string]string{
-				"Authorization": fmt.Sprintf("Bearer %s", testData.authToken),
-			},
 			PathParams: map[string]string{
 				"limitID": testData.createLimitMessage.ID,
 			},
@@ -296,7 +284,7 @@ func (s *CasinoLossLimitSuite) TestCasinoLossLimitUpdate(t provider.T) {
 			},
 		}
 
-		resp := s.Shared.PublicClient.UpdateRecalculatedLimit(sCtx, testData.updateRecalculatedLimitRequest)
+		resp := testData.session.UpdateRecalculatedLimit(sCtx, testData.updateRecalculatedLimitRequest)
 		sCtx.Require().Equal(http.StatusOK, resp.StatusCode, "Лимит на проигрыш обновлен")
 	})
 
@@ -309,7 +297,7 @@ func (s *CasinoLossLimitSuite) TestCasinoLossLimitUpdate(t provider.T) {
 				msg.ID == testData.createLimitMessage.ID &&
 				msg.Amount == testData.updateRecalculatedLimitRequest.Body.Amount &&
 				msg.CurrencyCode == testData.casinoLossLimitRequest.Body.Currency
-		})
+		}, kafka.ByField("playerId", testData.walletAggregate.PlayerUUID))
 
 		sCtx.Require().NotEmpty(testData.updateLimitMessage.ID, "Сообщение об обновлении лимита на проигрыш из топика limits.v2 получено")
 	})
@@ -338,13 +326,9 @@ func (s *CasinoLossLimitSuite) TestCasinoLossLimitUpdate(t provider.T) {
 	})
 
 	t.WithNewAsyncStep("Получение обновленного лимита через Public API", func(sCtx provider.StepCtx) {
-		req := &clientTypes.Request[any]{
-			Headers: map[string]string{
-				"Authorization": fmt.Sprintf("Bearer %s", testData.authToken),
-			},
-		}
+		req := &clientTypes.Request[any]{}
 
-		testData.casinoLossLimitResponse = s.Shared.PublicClient.GetCasinoLossLimits(sCtx, req)
+		testData.casinoLossLimitResponse = testData.session.GetCasinoLossLimits(sCtx, req)
 		sCtx.Assert().Equal(http.StatusOK, testData.casinoLossLimitResponse.StatusCode, "Public API: Статус-код 200")
 
 		limit := testData.casinoLossLimitResponse.Body[0]
@@ -410,7 +394,7 @@ func (s *CasinoLossLimitSuite) TestCasinoLossLimitUpdate(t provider.T) {
 				msg.PlayerUUID == testData.walletAggregate.PlayerUUID &&
 				msg.WalletUUID == testData.walletAggregate.WalletUUID &&
 				msg.SeqNumber == int(testData.casinoLossUpdateEvent.Seq)
-		})
+		}, kafka.ByField("player_uuid", testData.walletAggregate.PlayerUUID))
 		sCtx.Assert().NotEmpty(projectionMessage.Type, "Kafka: Сообщение projectionSource найдено")
 
 		sCtx.Assert().Equal(string(kafka.ProjectionEventLimitChanged), string(projectionMessage.Type), "Kafka: Проверка параметра type")
@@ -446,7 +430,7 @@ func (s *CasinoLossLimitSuite) TestCasinoLossLimitReset(t provider.T) {
 	t.Tags("wallet", "limits")
 
 	var testData struct {
-		authToken               string
+		session                 *publicAPI.PlayerSession
 		walletAggregate         redis.WalletFullData
 		casinoLossLimitResponse *clientTypes.Response[publicModels.GetCasinoLossLimitsResponseBody]
 		casinoLossLimitRequest  *clientTypes.Request[publicModels.SetCasinoLossLimitRequestBody]
@@ -469,15 +453,12 @@ func (s *CasinoLossLimitSuite) TestCasinoLossLimitReset(t provider.T) {
 		sCtx.Require().NotEmpty(playerData.WalletData.WalletUUID, "UUID кошелька получен")
 		sCtx.Require().NotEmpty(playerData.WalletData.PlayerUUID, "UUID игрока получен")
 
-		testData.authToken = playerData.Auth.Body.Token
+		testData.session = playerData.Session
 		testData.walletAggregate = playerData.WalletData
 	})
 
 	t.WithNewStep("Установка лимита на проигрыш", func(sCtx provider.StepCtx) {
 		testData.casinoLossLimitRequest = &clientTypes.Request[publicModels.SetCasinoLossLimitRequestBody]{
-			Headers: map[string]string{
-				"Authorization": fmt.Sprintf("Bearer %s", testData.authToken),
-			},
 			Body: &publicModels.SetCasinoLossLimitRequestBody{
 				Amount:    "100",
 				Currency:  s.Shared.Config.Node.DefaultCurrency,
@@ -486,7 +467,7 @@ func (s *CasinoLossLimitSuite) TestCasinoLossLimitReset(t provider.T) {
 			},
 		}
 
-		resp := s.Shared.PublicClient.SetCasinoLossLimit(sCtx, testData.casinoLossLimitRequest)
+		resp := testData.session.SetCasinoLossLimit(sCtx, testData.casinoLossLimitRequest)
 		sCtx.Require().Equal(http.StatusCreated, resp.StatusCode, "Лимит на проигрыш установлен")
 	})
 
@@ -498,7 +479,7 @@ func (s *CasinoLossLimitSuite) TestCasinoLossLimitReset(t provider.T) {
 				msg.PlayerID == testData.walletAggregate.PlayerUUID &&
 				msg.Amount == testData.casinoLossLimitRequest.Body.Amount &&
 				msg.CurrencyCode == testData.casinoLossLimitRequest.Body.Currency
-		})
+		}, kafka.ByField("playerId", testData.walletAggregate.PlayerUUID))
 		sCtx.Require().NotEmpty(testData.limitMessage.ID, "Сообщение о создании лимита на проигрыш из топика limits.v2 получено")
 	})
 
@@ -561,13 +542,9 @@ func (s *CasinoLossLimitSuite) TestCasinoLossLimitReset(t provider.T) {
 	})
 
 	t.WithNewAsyncStep("Получение сброшенного лимита через Public API", func(sCtx provider.StepCtx) {
-		req := &clientTypes.Request[any]{
-			Headers: map[string]string{
-				"Authorization": fmt.Sprintf("Bearer %s", testData.authToken),
-			},
-		}
+		req := &clientTypes.Request[any]{}
 
-		testData.casinoLossLimitResponse = s.Shared.PublicClient.GetCasinoLossLimits(sCtx, req)
+		testData.casinoLossLimitResponse = testData.session.GetCasinoLossLimits(sCtx, req)
 		sCtx.Assert().Equal(http.StatusOK, testData.casinoLossLimitResponse.StatusCode, "Public API: Статус-код 200")
 
 		limit := testData.casinoLossLimitResponse.Body[0]
@@ -635,7 +612,7 @@ func (s *CasinoLossLimitSuite) TestCasinoLossLimitReset(t provider.T) {
 				msg.PlayerUUID == testData.walletAggregate.PlayerUUID &&
 				msg.WalletUUID == testData.walletAggregate.WalletUUID &&
 				msg.SeqNumber == int(testData.casinoLossEventReset.Seq)
-		})
+		}, kafka.ByField("player_uuid", testData.walletAggregate.PlayerUUID))
 		sCtx.Assert().NotEmpty(projectionMessage.Type, "Kafka: Сообщение projectionSource найдено")
 		sCtx.Assert().Equal(string(kafka.ProjectionEventLimitChanged), string(projectionMessage.Type), "Kafka: Проверка параметра type")
 		sCtx.Assert().Equal(testData.casinoLossEventReset.Sequence, projectionMessage.SeqNumber, "Kafka: Проверка параметра seq_number")
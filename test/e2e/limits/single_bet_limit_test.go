@@ -82,7 +82,7 @@ func (s *SingleBetLimitSuite) TestSingleBetLimitCreate(t provider.T) {
 				msg.PlayerID == testData.walletAggregate.PlayerUUID &&
 				msg.Amount == testData.singleBetLimitRequest.Body.Amount &&
 				msg.CurrencyCode == testData.singleBetLimitRequest.Body.Currency
-		})
+		}, kafka.ByField("playerId", testData.walletAggregate.PlayerUUID))
 		sCtx.Require().NotEmpty(testData.limitMessage.ID, "Сообщение о создании лимита на одиночную ставку из топика limits.v2 получено")
 	})
 
@@ -174,7 +174,7 @@ func (s *SingleBetLimitSuite) TestSingleBetLimitCreate(t provider.T) {
 			return msg.Type == kafka.ProjectionEventLimitChanged &&
 				msg.PlayerUUID == testData.walletAggregate.PlayerUUID &&
 				msg.WalletUUID == testData.walletAggregate.WalletUUID
-		})
+		}, kafka.ByField("player_uuid", testData.walletAggregate.PlayerUUID))
 		sCtx.Assert().NotEmpty(projectionMessage.Type, "Kafka: Сообщение projectionSource найдено")
 
 		sCtx.Assert().Equal(string(kafka.ProjectionEventLimitChanged), string(projectionMessage.Type), "Kafka: Проверка параметра type")
@@ -281,7 +281,7 @@ func (s *SingleBetLimitSuite) TestSingleBetLimitUpdate(t provider.T) {
 				msg.PlayerID == testData.walletAggregate.PlayerUUID &&
 				msg.Amount == testData.singleBetLimitRequest.Body.Amount &&
 				msg.CurrencyCode == testData.singleBetLimitRequest.Body.Currency
-		})
+		}, kafka.ByField("playerId", testData.walletAggregate.PlayerUUID))
 
 		sCtx.Require().NotEmpty(testData.createLimitMessage.ID, "Сообщение о создании лимита на одиночную ставку из топика limits.v2 получено")
 	})
@@ -314,7 +314,7 @@ func (s *SingleBetLimitSuite) TestSingleBetLimitUpdate(t provider.T) {
 				msg.ID == testData.createLimitMessage.ID &&
 				msg.Amount == testData.updateSingeBetLimitRequest.Body.Amount &&
 				msg.CurrencyCode == testData.singleBetLimitRequest.Body.Currency
-		})
+		}, kafka.ByField("playerId", testData.walletAggregate.PlayerUUID))
 
 		sCtx.Require().NotEmpty(testData.updateLimitMessage.ID, "Сообщение об обновлении лимита на одиночную ставку из топика limits.v2 получено")
 	})
@@ -409,7 +409,7 @@ func (s *SingleBetLimitSuite) TestSingleBetLimitUpdate(t provider.T) {
 				msg.PlayerUUID == testData.walletAggregate.PlayerUUID &&
 				msg.WalletUUID == testData.walletAggregate.WalletUUID &&
 				msg.SeqNumber == int(testData.singleBetUpdateEvent.Seq)
-		})
+		}, kafka.ByField("player_uuid", testData.walletAggregate.PlayerUUID))
 		sCtx.Assert().NotEmpty(projectionMessage.Type, "Kafka: Сообщение projectionSource найдено")
 
 		sCtx.Assert().Equal(string(kafka.ProjectionEventLimitChanged), string(projectionMessage.Type), "Kafka: Проверка параметра type")
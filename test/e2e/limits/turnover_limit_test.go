@@ -86,7 +86,7 @@ func (s *TurnoverLimitSuite) TestTurnoverLimitCreate(t provider.T) {
 				msg.PlayerID == testData.walletAggregate.PlayerUUID &&
 				msg.Amount == testData.turnoverLimitRequest.Body.Amount &&
 				msg.CurrencyCode == testData.turnoverLimitRequest.Body.Currency
-		})
+		}, kafka.ByField("playerId", testData.walletAggregate.PlayerUUID))
 		sCtx.Require().NotEmpty(testData.limitMessage.ID, "Сообщение о создании лимита на проигрыш из топика limits.v2 получено")
 	})
 
@@ -186,7 +186,7 @@ func (s *TurnoverLimitSuite) TestTurnoverLimitCreate(t provider.T) {
 			return msg.Type == kafka.ProjectionEventLimitChanged &&
 				msg.PlayerUUID == testData.walletAggregate.PlayerUUID &&
 				msg.WalletUUID == testData.walletAggregate.WalletUUID
-		})
+		}, kafka.ByField("player_uuid", testData.walletAggregate.PlayerUUID))
 		sCtx.Assert().NotEmpty(projectionMessage.Type, "Kafka: Сообщение projectionSource найдено")
 		sCtx.Assert().Equal(string(kafka.ProjectionEventLimitChanged), string(projectionMessage.Type), "Kafka: Проверка параметра type")
 		sCtx.Assert().Equal(testData.turnoverEvent.Sequence, projectionMessage.SeqNumber, "Kafka: Проверка параметра seq_number")
@@ -295,7 +295,7 @@ func (s *TurnoverLimitSuite) TestTurnoverLimitUpdate(t provider.T) {
 				msg.PlayerID == testData.walletAggregate.PlayerUUID &&
 				msg.Amount == testData.turnoverLimitRequest.Body.Amount &&
 				msg.CurrencyCode == testData.turnoverLimitRequest.Body.Currency
-		})
+		}, kafka.ByField("playerId", testData.walletAggregate.PlayerUUID))
 		sCtx.Require().NotEmpty(testData.createLimitMessage.ID, "Сообщение о создании лимита на оборот средств из топика limits.v2 получено")
 	})
 
@@ -327,7 +327,7 @@ func (s *TurnoverLimitSuite) TestTurnoverLimitUpdate(t provider.T) {
 				msg.ID == testData.createLimitMessage.ID &&
 				msg.Amount == testData.updateRecalculatedLimitRequest.Body.Amount &&
 				msg.CurrencyCode == testData.turnoverLimitRequest.Body.Currency
-		})
+		}, kafka.ByField("playerId", testData.walletAggregate.PlayerUUID))
 
 		sCtx.Require().NotEmpty(testData.updateLimitMessage.ID, "Сообщение об обновлении лимита на оборот средств из топика limits.v2 получено")
 	})
@@ -426,7 +426,7 @@ func (s *TurnoverLimitSuite) TestTurnoverLimitUpdate(t provider.T) {
 				msg.PlayerUUID == testData.walletAggregate.PlayerUUID &&
 				msg.WalletUUID == testData.walletAggregate.WalletUUID &&
 				msg.SeqNumber == int(testData.turnoverUpdateEvent.Seq)
-		})
+		}, kafka.ByField("player_uuid", testData.walletAggregate.PlayerUUID))
 		sCtx.Assert().NotEmpty(projectionMessage.Type, "Kafka: Сообщение projectionSource найдено")
 
 		sCtx.Assert().Equal(string(kafka.ProjectionEventLimitChanged), string(projectionMessage.Type), "Kafka: Проверка параметра type")
@@ -514,7 +514,7 @@ func (s *TurnoverLimitSuite) TestTurnoverLimitReset(t provider.T) {
 				msg.PlayerID == testData.walletAggregate.PlayerUUID &&
 				msg.Amount == testData.turnoverLimitRequest.Body.Amount &&
 				msg.CurrencyCode == testData.turnoverLimitRequest.Body.Currency
-		})
+		}, kafka.ByField("playerId", testData.walletAggregate.PlayerUUID))
 		sCtx.Require().NotEmpty(testData.limitMessage.ID, "Сообщение о создании лимита на оборот средств из топика limits.v2 получено")
 	})
 
@@ -651,7 +651,7 @@ func (s *TurnoverLimitSuite) TestTurnoverLimitReset(t provider.T) {
 				msg.PlayerUUID == testData.walletAggregate.PlayerUUID &&
 				msg.WalletUUID == testData.walletAggregate.WalletUUID &&
 				msg.SeqNumber == int(testData.turnoverEventReset.Seq)
-		})
+		}, kafka.ByField("player_uuid", testData.walletAggregate.PlayerUUID))
 		sCtx.Assert().NotEmpty(projectionMessage.Type, "Kafka: Сообщение projectionSource найдено")
 		sCtx.Assert().Equal(string(kafka.ProjectionEventLimitChanged), string(projectionMessage.Type), "Kafka: Проверка параметра type")
 		sCtx.Assert().Equal(testData.turnoverEventReset.Sequence, projectionMessage.SeqNumber, "Kafka: Проверка параметра seq_number")
@@ -100,7 +100,7 @@ func (s *BalanceAdjustmentSuite) TestBalanceAdjustment(t provider.T) {
 		testData.registrationMessage = kafka.FindMessageByFilter(sCtx, s.kafka, func(msg kafka.PlayerMessage) bool {
 			return msg.Message.EventType == kafka.PlayerEventSignUpFast &&
 				msg.Player.AccountID == testData.registrationResponse.Body.Username
-		})
+		}, kafka.ByField("player.accountId", testData.registrationResponse.Body.Username))
 
 		sCtx.Require().NotEmpty(testData.registrationMessage.Player.ExternalID, "External ID игрока в сообщении регистрации не пустой")
 	})
@@ -199,7 +199,7 @@ func (s *BalanceAdjustmentSuite) TestBalanceAdjustment(t provider.T) {
 			return msg.Type == kafka.ProjectionEventBalanceAdjusted &&
 				msg.PlayerUUID == testData.registrationMessage.Player.ExternalID &&
 				msg.WalletUUID == testData.walletCreatedEvent.Payload.WalletUUID
-		})
+		}, kafka.ByField("player_uuid", testData.registrationMessage.Player.ExternalID))
 
 		sCtx.Require().NotEmpty(testData.projectionAdjustEvent.Type, "Сообщение balance_adjusted найдено в топике projection source")
 
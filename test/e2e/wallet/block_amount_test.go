@@ -99,7 +99,7 @@ func (s *BlockAmountSuite) TestBlockAmount(t provider.T) {
 		testData.registrationMessage = kafka.FindMessageByFilter(sCtx, s.kafka, func(msg kafka.PlayerMessage) bool {
 			return msg.Message.EventType == kafka.PlayerEventSignUpFast &&
 				msg.Player.AccountID == testData.registrationResponse.Body.Username
-		})
+		}, kafka.ByField("player.accountId", testData.registrationResponse.Body.Username))
 
 		sCtx.Require().NotEmpty(testData.registrationMessage.Player.ExternalID, "External ID игрока в сообщении регистрации не пустой")
 	})
@@ -94,7 +94,7 @@ func (s *CreateWalletSuite) TestCreateWallet(t provider.T) {
 		testData.registrationMessage = kafka.FindMessageByFilter(sCtx, s.kafka, func(msg kafka.PlayerMessage) bool {
 			return msg.Message.EventType == kafka.PlayerEventSignUpFast &&
 				msg.Player.AccountID == testData.registrationResponse.Body.Username
-		})
+		}, kafka.ByField("player.accountId", testData.registrationResponse.Body.Username))
 
 		sCtx.Require().NotEmpty(testData.registrationMessage.Player.ID, "ID игрока не пустой")
 	})
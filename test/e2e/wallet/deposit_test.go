@@ -130,7 +130,7 @@ func (s *SingleBetLimitSuite) TestSingleBetLimit(t provider.T) {
 				msg.Transaction.Amount == testData.depositRequest.Body.Amount &&
 				msg.Transaction.CurrencyCode == testData.depositRequest.Body.Currency &&
 				msg.Transaction.Status == kafka.TransactionStatusSuccess
-		})
+		}, kafka.ByField("playerId", testData.walletAggregate.PlayerUUID))
 		sCtx.Require().NotEmpty(testData.transactionMessage.Transaction.TransactionID, "Kafka: Сообщение о транзакции найдено")
 		sCtx.Assert().Equal(testData.walletAggregate.PlayerUUID, testData.transactionMessage.PlayerID, "Kafka: Проверка параметра playerId")
 		sCtx.Assert().Equal(s.config.Node.ProjectID, testData.transactionMessage.NodeID, "Kafka: Проверка параметра nodeId")
@@ -166,7 +166,7 @@ func (s *SingleBetLimitSuite) TestSingleBetLimit(t provider.T) {
 			return msg.Type == kafka.ProjectionEventDepositedMoney &&
 				msg.PlayerUUID == testData.walletAggregate.PlayerUUID &&
 				msg.WalletUUID == testData.walletAggregate.WalletUUID
-		})
+		}, kafka.ByField("player_uuid", testData.walletAggregate.PlayerUUID))
 		sCtx.Require().NotEmpty(testData.projectionMessage.Type, "Kafka: Сообщение о депозите в projectionSource найдено")
 
 		sCtx.Assert().Equal(string(kafka.ProjectionEventDepositedMoney), string(testData.projectionMessage.Type), "Kafka: Проверка параметра type")
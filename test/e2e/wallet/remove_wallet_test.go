@@ -93,7 +93,7 @@ func (s *RemoveWalletSuite) TestRemoveWallet(t provider.T) {
 	t.WithNewStep("Получение сообщения о регистрации игрока из Kafka.", func(sCtx provider.StepCtx) {
 		testData.registrationMessage = kafka.FindMessageByFilter(sCtx, s.kafka, func(msg kafka.PlayerMessage) bool {
 			return msg.Player.AccountID == testData.registrationResponse.Body.Username
-		})
+		}, kafka.ByField("player.accountId", testData.registrationResponse.Body.Username))
 
 		sCtx.Require().NotEmpty(testData.registrationMessage.Player.ExternalID, "External ID игрока в регистрации не пустой")
 	})